@@ -4,17 +4,231 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/leobishop234/deepcover/src/config"
 	"github.com/leobishop234/deepcover/src/cover"
 	"github.com/leobishop234/deepcover/src/out"
+	"golang.org/x/tools/go/packages"
 )
 
+type output struct {
+	format string
+	path   string
+}
+
+type repeatedFlag []string
+
+func (o *repeatedFlag) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *repeatedFlag) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
+
+// analysisFlags are the flags shared by every command that runs the analysis.
+type analysisFlags struct {
+	target     string
+	configPath string
+	testFlags  string
+	include    repeatedFlag
+	exclude    repeatedFlag
+	options    cover.Options
+}
+
+func (a *analysisFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&a.target, "run", "Test", "Unanchored regular expression that matches target test names")
+	flags.StringVar(&a.options.Algorithm, "algorithm", "cha", "Call graph algorithm ("+strings.Join(cover.Algorithms, ", ")+")")
+	flags.StringVar(&a.testFlags, "testflags", "", "Extra flags passed to go test, separated by spaces")
+	flags.Var(&a.include, "include", "Regular expression, or glob: prefixed glob, matching package.Function names of the dependencies to report (repeatable)")
+	flags.Var(&a.exclude, "exclude", "Regular expression, or glob: prefixed glob, matching package.Function names of the dependencies to leave out (repeatable)")
+	flags.BoolVar(&a.options.IncludeGenerated, "generated", false, "Report functions in generated files")
+	flags.BoolVar(&a.options.TestHelpers, "testhelpers", false, "Report the functions declared in test files that the tests reach in a separate section")
+	flags.BoolVar(&a.options.TypeArguments, "typeargs", false, "Show the type arguments each generic function is instantiated with")
+	flags.BoolVar(&a.options.Initializers, "initializers", false, "Report the functions only reached through package initialization in a separate section")
+	flags.StringVar(&a.configPath, "config", "", "Path to the config file (default "+config.FileName+" at the module root)")
+}
+
+// resolve applies the config file for pkgPath to the flags in flags that were
+// not set on the command line.
+func (a *analysisFlags) resolve(flags *flag.FlagSet, pkgPath string) error {
+	cfg, err := loadConfig(a.configPath, pkgPath)
+	if err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	values := map[string][]string{
+		"run":       {cfg.Run},
+		"algorithm": {cfg.Algorithm},
+		"format":    {cfg.Format},
+		"o":         cfg.Outputs,
+	}
+	if cfg.Threshold != nil {
+		values["threshold"] = []string{strconv.FormatFloat(*cfg.Threshold, 'f', -1, 64)}
+	}
+	if cfg.Generated != nil {
+		values["generated"] = []string{strconv.FormatBool(*cfg.Generated)}
+	}
+	if cfg.TestHelpers != nil {
+		values["testhelpers"] = []string{strconv.FormatBool(*cfg.TestHelpers)}
+	}
+	if cfg.TypeArguments != nil {
+		values["typeargs"] = []string{strconv.FormatBool(*cfg.TypeArguments)}
+	}
+	if cfg.Initializers != nil {
+		values["initializers"] = []string{strconv.FormatBool(*cfg.Initializers)}
+	}
+
+	for name, values := range values {
+		if set[name] || flags.Lookup(name) == nil {
+			continue
+		}
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if err := flags.Set(name, value); err != nil {
+				return fmt.Errorf("invalid %s in config: %v", name, err)
+			}
+		}
+	}
+
+	a.options.TestFlags = strings.Fields(a.testFlags)
+	if !set["testflags"] && cfg.TestFlags != nil {
+		a.options.TestFlags = cfg.TestFlags
+	}
+
+	a.options.Include = a.include
+	if !set["include"] {
+		a.options.Include = cfg.Include
+	}
+	a.options.Exclude = a.exclude
+	if !set["exclude"] {
+		a.options.Exclude = cfg.Exclude
+	}
+
+	return nil
+}
+
+// loadConfig loads the config file at configPath, or the one at the root of
+// the module containing the packages matched by pkgPath if configPath is
+// empty, with the overrides for those packages applied.
+func loadConfig(configPath, pkgPath string) (config.Config, error) {
+	pkgDirs, err := packageDirs(pkgPath)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	if configPath == "" {
+		found, err := config.Find(pkgDirs[0])
+		if err != nil || found == "" {
+			return config.Config{}, err
+		}
+		configPath = found
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return config.Config{}, fmt.Errorf("failed to resolve config path: %v", err)
+	}
+
+	rels := make([]string, len(pkgDirs))
+	for i, dir := range pkgDirs {
+		rel, err := filepath.Rel(configDir, dir)
+		if err != nil {
+			return config.Config{}, fmt.Errorf("failed to resolve package directory: %v", err)
+		}
+		rels[i] = rel
+	}
+
+	return cfg.ForPackages(rels)
+}
+
+// packageDirs returns the directories of the packages matched by pkgPath,
+// which may be a directory, an import path or a pattern like "./...".
+func packageDirs(pkgPath string) ([]string, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedModule,
+	}, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %v", err)
+	}
+
+	dirs := []string{}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("failed to load package %s: %v", pkg.PkgPath, pkg.Errors)
+		}
+		if pkg.Module == nil {
+			return nil, fmt.Errorf("package %s is not in a module", pkg.PkgPath)
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(pkg.PkgPath, pkg.Module.Path), "/")
+		dirs = append(dirs, filepath.Join(pkg.Module.Dir, filepath.FromSlash(rel)))
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no packages found")
+	}
+
+	return dirs, nil
+}
+
 func main() {
-	var target string
-	var output string
+	if len(os.Args) > 1 {
+		var subcommand func(args []string) error
+		switch os.Args[1] {
+		case "tui":
+			subcommand = tui
+		case "serve":
+			subcommand = serve
+		}
+
+		if subcommand != nil {
+			if err := subcommand(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	var analysis analysisFlags
+	var outputs repeatedFlag
+	var format string
+	var options out.Options
+	var baseline string
+	var watch bool
+	var interval time.Duration
 
-	flag.StringVar(&target, "run", "Test", "Unanchored regular expression that matches target test names")
-	flag.StringVar(&output, "o", "", "Output file path")
+	analysis.register(flag.CommandLine)
+	flag.Var(&outputs, "o", "Output as format=path, a format name to write to the terminal, or a file path for -format (repeatable)")
+	flag.StringVar(&format, "format", "text", "Output format ("+strings.Join(out.Formats(), ", ")+")")
+	flag.Float64Var(&options.Threshold, "threshold", 100, "Coverage percentage below which a dependency is reported (sarif, github)")
+	flag.StringVar(&baseline, "baseline", "", "Coverage file from a previous run to compare against (markdown)")
+	flag.StringVar(&options.Template, "template", "", "Path to a text/template used to render the report instead of -format")
+	flag.StringVar(&options.Sort, "sort", "path", "Terminal table sort order ("+strings.Join(out.SortKeys, ", ")+")")
+	flag.StringVar(&options.View, "view", "table", "Terminal view ("+strings.Join(out.Views, ", ")+")")
+	flag.BoolVar(&watch, "watch", false, "Re-run the tests affected by changes to the module's Go files, analysing only their packages, and print the coverage delta")
+	flag.DurationVar(&interval, "interval", time.Second, "How often -watch checks for changes")
 
 	flag.Parse()
 
@@ -25,29 +239,260 @@ func main() {
 	}
 	pkgPath := args[0]
 
-	if err := run(pkgPath, target, output); err != nil {
+	if err := analysis.resolve(flag.CommandLine, pkgPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !slices.Contains(out.SortKeys, options.Sort) {
+		fmt.Fprintf(os.Stderr, "Error: unknown sort key %q\n", options.Sort)
+		os.Exit(1)
+	}
+
+	if !slices.Contains(out.Views, options.View) {
+		fmt.Fprintf(os.Stderr, "Error: unknown view %q\n", options.View)
+		os.Exit(1)
+	}
+
+	if options.Template != "" {
+		format = "template"
+	}
+
+	if baseline != "" {
+		previous, err := out.ReadFile(baseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read baseline: %v\n", err)
+			os.Exit(1)
+		}
+		options.Baseline = &previous
+	}
+
+	parsedOutputs, err := parseOutputs(outputs, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if watch {
+		err = watchChanges(pkgPath, analysis, parsedOutputs, options, interval)
+	} else {
+		err = run(pkgPath, analysis, parsedOutputs, options)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(pkgPath, target, output string) error {
+// tui runs the interactive browser: deepcover tui [flags] <package-path>
+func tui(args []string) error {
+	var analysis analysisFlags
+	flags := flag.NewFlagSet("tui", flag.ExitOnError)
+	analysis.register(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected path to target package as argument")
+	}
+	pkgPath := flags.Arg(0)
+
+	if err := analysis.resolve(flags, pkgPath); err != nil {
+		return err
+	}
+
+	coverage, err := cover.Deepcover(pkgPath, analysis.target, analysis.options)
+	if err != nil {
+		return fmt.Errorf("failed to get dependencies: %v", err)
+	}
+
+	return out.Browse(coverage, func(test cover.Test) (cover.Result, error) {
+		return cover.Deepcover(pkgPath, "^"+regexp.QuoteMeta(test.Name)+"$", analysis.options)
+	})
+}
+
+// serve serves an interactive report: deepcover serve [-addr address] [flags] <package-path>
+func serve(args []string) error {
+	var analysis analysisFlags
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "Address to serve the report on")
+	analysis.register(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected path to target package as argument")
+	}
+	pkgPath := flags.Arg(0)
+
+	if err := analysis.resolve(flags, pkgPath); err != nil {
+		return err
+	}
+
+	analyze := func() (cover.Result, error) {
+		return cover.Deepcover(pkgPath, analysis.target, analysis.options)
+	}
+
+	coverage, err := analyze()
+	if err != nil {
+		return fmt.Errorf("failed to get dependencies: %v", err)
+	}
+
+	fmt.Printf("Serving deepcover report on http://%s\n", *addr)
+	return out.Serve(*addr, coverage, analyze)
+}
+
+// parseOutputs resolves each -o value to a format and path. A value is either
+// format=path, a bare format name written to the terminal, or a file path
+// written with the default format.
+func parseOutputs(values []string, defaultFormat string) ([]output, error) {
+	if _, ok := out.Lookup(defaultFormat); !ok {
+		return nil, fmt.Errorf("unknown output format %q", defaultFormat)
+	}
+
+	if len(values) == 0 {
+		return []output{{format: defaultFormat}}, nil
+	}
+
+	outputs := make([]output, 0, len(values))
+	for _, value := range values {
+		if format, path, ok := strings.Cut(value, "="); ok {
+			if _, known := out.Lookup(format); known {
+				outputs = append(outputs, output{format: format, path: path})
+				continue
+			}
+		}
+
+		if _, known := out.Lookup(value); known {
+			outputs = append(outputs, output{format: value})
+			continue
+		}
+
+		outputs = append(outputs, output{format: defaultFormat, path: value})
+	}
+
+	return outputs, nil
+}
+
+func run(pkgPath string, analysis analysisFlags, outputs []output, options out.Options) error {
 	if pkgPath == "" {
 		return fmt.Errorf("pkg path is required")
 	}
 
-	coverage, err := cover.Deepcover(pkgPath, target)
+	coverage, err := cover.Deepcover(pkgPath, analysis.target, analysis.options)
 	if err != nil {
 		return fmt.Errorf("failed to get dependencies: %v", err)
 	}
 
-	if output != "" {
-		if err := out.OutputFile(output, coverage); err != nil {
-			return fmt.Errorf("failed to save coverage to file: %v", err)
+	return writeOutputs(outputs, coverage, options)
+}
+
+func writeOutputs(outputs []output, coverage cover.Result, options out.Options) error {
+	for _, o := range outputs {
+		if err := out.Output(o.format, o.path, coverage, options); err != nil {
+			return fmt.Errorf("failed to write %s output: %v", o.format, err)
 		}
-	} else {
-		out.OutputTerminal(coverage)
 	}
 
 	return nil
 }
+
+// rerunTests analyses only the packages declaring the tests to re-run, and
+// merges their results into coverage.
+func rerunTests(coverage cover.Result, testsByDir map[string][]cover.Function, options cover.Options) (cover.Result, error) {
+	dirs := make([]string, 0, len(testsByDir))
+	for dir := range testsByDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		names := make([]string, len(testsByDir[dir]))
+		for i, test := range testsByDir[dir] {
+			names[i] = test.Name
+		}
+
+		partial, err := cover.Deepcover(dir, cover.TestsPattern(names), options)
+		if err != nil {
+			return cover.Result{}, err
+		}
+		coverage = cover.Merge(coverage, partial, testsByDir[dir])
+	}
+
+	return coverage, nil
+}
+
+// watchChanges writes the outputs, then polls the module's Go files. When they
+// change, it re-runs the tests that reach a changed function, analysing only
+// the packages declaring them, prints the coverage delta and rewrites the file
+// outputs.
+func watchChanges(pkgPath string, analysis analysisFlags, outputs []output, options out.Options, interval time.Duration) error {
+	coverage, err := cover.Deepcover(pkgPath, analysis.target, analysis.options)
+	if err != nil {
+		return fmt.Errorf("failed to get dependencies: %v", err)
+	}
+	if err := writeOutputs(outputs, coverage, options); err != nil {
+		return err
+	}
+
+	fileOutputs := []output{}
+	for _, o := range outputs {
+		if o.path != "" {
+			fileOutputs = append(fileOutputs, o)
+		}
+	}
+
+	// snapshot is the source the coverage was computed from, and seen is the
+	// source as it was when last checked for changes.
+	snapshot, err := cover.TakeSnapshot(coverage.ModuleDir)
+	if err != nil {
+		return err
+	}
+	seen := snapshot
+
+	fmt.Printf("Watching %s for changes\n", coverage.ModuleDir)
+	for {
+		time.Sleep(interval)
+
+		next, err := cover.TakeSnapshot(coverage.ModuleDir)
+		if err != nil {
+			return err
+		}
+		if next.Equal(seen) {
+			continue
+		}
+		seen = next
+
+		changed, err := cover.ChangedFunctions(snapshot, next)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+
+		tests, err := cover.AffectedTests(coverage, changed, analysis.target)
+		if err != nil {
+			return err
+		}
+		if len(tests) == 0 {
+			snapshot = next
+			continue
+		}
+
+		names := make([]string, len(tests))
+		for i, test := range tests {
+			names[i] = path.Base(test.Package) + "." + test.Name
+		}
+
+		fmt.Printf("Re-running %s\n", strings.Join(names, ", "))
+		merged, err := rerunTests(coverage, cover.AffectedPackages(next, tests), analysis.options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to get dependencies: %v\n", err)
+			continue
+		}
+
+		out.OutputDelta(coverage, merged)
+		coverage, snapshot = merged, next
+
+		if err := writeOutputs(fileOutputs, coverage, options); err != nil {
+			return err
+		}
+	}
+}
@@ -2,16 +2,20 @@ module github.com/leobishop234/deepcover
 
 go 1.22
 
-require golang.org/x/tools v0.22.0
+require (
+	golang.org/x/term v0.21.0
+	golang.org/x/tools v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sys v0.21.0 // indirect
 )
 
 require (
 	github.com/stretchr/testify v1.10.0
-	golang.org/x/mod v0.18.0 // indirect
+	golang.org/x/mod v0.18.0
 	golang.org/x/sync v0.7.0 // indirect
 )
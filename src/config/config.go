@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the config file looked for at the module root.
+const FileName = ".deepcover.yaml"
+
+// Config holds defaults for the command line flags. Unset fields leave the
+// flag defaults in place.
+type Config struct {
+	Run           string   `yaml:"run"`
+	Algorithm     string   `yaml:"algorithm"`
+	Format        string   `yaml:"format"`
+	Outputs       []string `yaml:"outputs"`
+	Threshold     *float64 `yaml:"threshold"`
+	TestFlags     []string `yaml:"testflags"`
+	Include       []string `yaml:"include"`
+	Exclude       []string `yaml:"exclude"`
+	Generated     *bool    `yaml:"generated"`
+	TestHelpers   *bool    `yaml:"testhelpers"`
+	TypeArguments *bool    `yaml:"typeargs"`
+	Initializers  *bool    `yaml:"initializers"`
+
+	// Packages overrides the settings above for target packages matching the
+	// key, a path.Match pattern relative to the module root.
+	Packages map[string]Config `yaml:"packages"`
+}
+
+// Find returns the path of the config file at the root of the module
+// containing dir, or an empty path if there is none.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %v", err)
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			configPath := filepath.Join(dir, FileName)
+			if _, err := os.Stat(configPath); err != nil {
+				return "", nil
+			}
+			return configPath, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func Load(configPath string) (Config, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	return parseConfig(content)
+}
+
+func parseConfig(content []byte) (Config, error) {
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	for pattern, override := range config.Packages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Config{}, fmt.Errorf("invalid package pattern %q: %v", pattern, err)
+		}
+		if len(override.Packages) > 0 {
+			return Config{}, fmt.Errorf("package %q: overrides cannot be nested", pattern)
+		}
+	}
+
+	return config, nil
+}
+
+// ForPackage returns the config with the overrides for pkg applied, where pkg
+// is the target package directory relative to the module root. Overrides
+// are applied in order of their patterns, so later patterns take precedence.
+func (c Config) ForPackage(pkg string) Config {
+	pkg = path.Clean(filepath.ToSlash(pkg))
+
+	patterns := make([]string, 0, len(c.Packages))
+	for pattern := range c.Packages {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	resolved := c
+	resolved.Packages = nil
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(path.Clean(pattern), pkg); !matched {
+			continue
+		}
+
+		override := c.Packages[pattern]
+		if override.Run != "" {
+			resolved.Run = override.Run
+		}
+		if override.Algorithm != "" {
+			resolved.Algorithm = override.Algorithm
+		}
+		if override.Format != "" {
+			resolved.Format = override.Format
+		}
+		if override.Outputs != nil {
+			resolved.Outputs = override.Outputs
+		}
+		if override.Threshold != nil {
+			resolved.Threshold = override.Threshold
+		}
+		if override.TestFlags != nil {
+			resolved.TestFlags = override.TestFlags
+		}
+		if override.Include != nil {
+			resolved.Include = override.Include
+		}
+		if override.Exclude != nil {
+			resolved.Exclude = override.Exclude
+		}
+		if override.Generated != nil {
+			resolved.Generated = override.Generated
+		}
+		if override.TestHelpers != nil {
+			resolved.TestHelpers = override.TestHelpers
+		}
+		if override.TypeArguments != nil {
+			resolved.TypeArguments = override.TypeArguments
+		}
+		if override.Initializers != nil {
+			resolved.Initializers = override.Initializers
+		}
+	}
+
+	return resolved
+}
+
+// ForPackages returns the config with the overrides for pkgs applied, as
+// ForPackage does for each of them. A run applies a single config, so the
+// packages must resolve to the same one.
+func (c Config) ForPackages(pkgs []string) (Config, error) {
+	if len(pkgs) == 0 {
+		return Config{}, fmt.Errorf("no packages to apply the config to")
+	}
+
+	resolved := c.ForPackage(pkgs[0])
+	for _, pkg := range pkgs[1:] {
+		if !reflect.DeepEqual(c.ForPackage(pkg), resolved) {
+			return Config{}, fmt.Errorf("packages %q and %q have different overrides in the config, run them separately", pkgs[0], pkg)
+		}
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/mod\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg", "sub"), 0o755))
+
+	nested := filepath.Join(root, "nested")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(nested, "go.mod"), []byte("module example.com/nested\n"), 0o644))
+
+	configPath, err := Find(filepath.Join(root, "pkg", "sub"))
+	require.NoError(t, err)
+	assert.Empty(t, configPath)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, FileName), []byte("run: TestTop\n"), 0o644))
+
+	tests := []struct {
+		name           string
+		dir            string
+		expectedResult string
+	}{
+		{
+			name:           "module root",
+			dir:            root,
+			expectedResult: filepath.Join(root, FileName),
+		},
+		{
+			name:           "package in the module",
+			dir:            filepath.Join(root, "pkg", "sub"),
+			expectedResult: filepath.Join(root, FileName),
+		},
+		{
+			name:           "nested module without a config",
+			dir:            nested,
+			expectedResult: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath, err := Find(tt.dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, configPath)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(configPath, []byte(`run: Test.*Integration
+algorithm: cha
+format: markdown
+outputs:
+  - text
+  - lcov=lcov.info
+threshold: 80
+testflags: ["-race", "-tags=integration"]
+exclude: ["/mocks\\."]
+generated: true
+typeargs: true
+initializers: true
+packages:
+  internal/*:
+    threshold: 90
+    include: ["/internal/"]
+`), 0o644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+
+	threshold := 80.0
+	overrideThreshold := 90.0
+	generated := true
+	assert.Equal(t, Config{
+		Run:           "Test.*Integration",
+		Algorithm:     "cha",
+		Format:        "markdown",
+		Outputs:       []string{"text", "lcov=lcov.info"},
+		Threshold:     &threshold,
+		TestFlags:     []string{"-race", "-tags=integration"},
+		Exclude:       []string{"/mocks\\."},
+		Generated:     &generated,
+		TypeArguments: &generated,
+		Initializers:  &generated,
+		Packages: map[string]Config{
+			"internal/*": {Threshold: &overrideThreshold, Include: []string{"/internal/"}},
+		},
+	}, cfg)
+
+	_, err = Load(filepath.Join(t.TempDir(), FileName))
+	assert.Error(t, err)
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:    "empty",
+			content: "",
+		},
+		{
+			name:        "unknown field",
+			content:     "runs: Test\n",
+			expectedErr: "failed to parse config: yaml: unmarshal errors:\n  line 1: field runs not found in type config.Config",
+		},
+		{
+			name:        "invalid pattern",
+			content:     "packages:\n  \"[\":\n    run: Test\n",
+			expectedErr: `invalid package pattern "[": syntax error in pattern`,
+		},
+		{
+			name:        "nested overrides",
+			content:     "packages:\n  pkg:\n    packages:\n      sub:\n        run: Test\n",
+			expectedErr: `package "pkg": overrides cannot be nested`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig([]byte(tt.content))
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestForPackage(t *testing.T) {
+	threshold := 80.0
+	apiThreshold := 95.0
+
+	cfg := Config{
+		Run:       "Test",
+		Format:    "text",
+		Threshold: &threshold,
+		TestFlags: []string{"-race"},
+		Exclude:   []string{"/mocks\\."},
+		Packages: map[string]Config{
+			"internal/*": {
+				Run:       "TestInternal",
+				TestFlags: []string{},
+				Include:   []string{"/internal/"},
+			},
+			"internal/api": {
+				Threshold: &apiThreshold,
+				Outputs:   []string{"sarif=api.sarif"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		pkg            string
+		expectedResult Config
+	}{
+		{
+			name: "no override",
+			pkg:  "cmd",
+			expectedResult: Config{
+				Run:       "Test",
+				Format:    "text",
+				Threshold: &threshold,
+				TestFlags: []string{"-race"},
+				Exclude:   []string{"/mocks\\."},
+			},
+		},
+		{
+			name: "pattern override",
+			pkg:  "./internal/store/",
+			expectedResult: Config{
+				Run:       "TestInternal",
+				Format:    "text",
+				Threshold: &threshold,
+				TestFlags: []string{},
+				Include:   []string{"/internal/"},
+				Exclude:   []string{"/mocks\\."},
+			},
+		},
+		{
+			name: "overrides applied in pattern order",
+			pkg:  "internal/api",
+			expectedResult: Config{
+				Run:       "TestInternal",
+				Format:    "text",
+				Outputs:   []string{"sarif=api.sarif"},
+				Threshold: &apiThreshold,
+				TestFlags: []string{},
+				Include:   []string{"/internal/"},
+				Exclude:   []string{"/mocks\\."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, cfg.ForPackage(tt.pkg))
+		})
+	}
+}
+
+func TestForPackages(t *testing.T) {
+	cfg := Config{
+		Run: "Test",
+		Packages: map[string]Config{
+			"internal/*": {Run: "TestInternal"},
+		},
+	}
+
+	got, err := cfg.ForPackages([]string{"cmd", "pkg/sub"})
+	require.NoError(t, err)
+	assert.Equal(t, Config{Run: "Test"}, got)
+
+	got, err = cfg.ForPackages([]string{"internal/api", "internal/store"})
+	require.NoError(t, err)
+	assert.Equal(t, Config{Run: "TestInternal"}, got)
+
+	_, err = cfg.ForPackages([]string{"cmd", "internal/api"})
+	assert.ErrorContains(t, err, `packages "cmd" and "internal/api" have different overrides`)
+
+	_, err = cfg.ForPackages(nil)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"go/token"
+	"go/types"
 	"regexp"
 
 	"golang.org/x/tools/go/callgraph"
@@ -25,10 +26,25 @@ func buildAnalysis(path string, targetRegex *regexp.Regexp) (analysis, error) {
 	}
 
 	targetSSAs := findTargetSSAFunctions(ssaPkgs, targetRegex)
+	testMains := findTestMains(ssaPkgs)
+
+	directives := readDirectives(pkgs)
+	rootSSAs, err := findRootSSAFunctions(ssaProg, directives.roots)
+	if err != nil {
+		return analysis{}, err
+	}
+	for functionID, rootSSA := range rootSSAs {
+		targetSSAs[functionID] = rootSSA
+	}
 
 	results := analysis{
-		callgraph:   cha.CallGraph(ssaProg),
-		targetNodes: make(map[functionID]*callgraph.Node, len(targetSSAs)),
+		callgraph:      cha.CallGraph(ssaProg),
+		targetNodes:    make(map[functionID]*callgraph.Node, len(targetSSAs)),
+		initNodes:      make(map[functionID]*callgraph.Node, len(targetSSAs)),
+		setupNodes:     make(map[functionID]*callgraph.Node, len(targetSSAs)),
+		moduleDir:      findModuleDir(pkgs),
+		ignored:        directives.ignored,
+		generatedFiles: directives.generated,
 	}
 
 	for functionID, targetSSA := range targetSSAs {
@@ -37,6 +53,21 @@ func buildAnalysis(path string, targetRegex *regexp.Regexp) (analysis, error) {
 			return analysis{}, fmt.Errorf("failed to find callgraph node for function %s", targetSSA.Name())
 		}
 		results.targetNodes[functionID] = targetNode
+
+		if init := targetSSA.Pkg.Func("init"); init != nil {
+			if initNode, ok := results.callgraph.Nodes[init]; ok {
+				results.initNodes[functionID] = initNode
+			}
+		}
+
+		if _, isRoot := rootSSAs[functionID]; isRoot {
+			continue
+		}
+		if testMain, ok := testMains[packageUnderTest(functionID.pkgPath)]; ok {
+			if setupNode, ok := results.callgraph.Nodes[testMain]; ok {
+				results.setupNodes[functionID] = setupNode
+			}
+		}
 	}
 
 	return results, nil
@@ -73,19 +104,46 @@ func loadPackages(conf *packages.Config, path string) ([]*packages.Package, erro
 	return pkgs, nil
 }
 
+func findModuleDir(pkgs []*packages.Package) string {
+	for _, pkg := range pkgs {
+		if pkg.Module != nil {
+			return pkg.Module.Dir
+		}
+	}
+
+	return ""
+}
+
 func buildSSAObjects(pkgs []*packages.Package) (*ssa.Program, []*ssa.Package, error) {
-	ssaProg, ssaPkgs := ssautil.AllPackages(pkgs, 0)
+	ssaProg, ssaPkgs := ssautil.AllPackages(pkgs, ssa.InstantiateGenerics)
 	ssaProg.Build()
 
 	return ssaProg, ssaPkgs, nil
 }
 
+// funcValue returns the function implementing obj, which may be a method. It
+// returns nil for interface methods and the methods of generic types.
+func funcValue(prog *ssa.Program, obj *types.Func) *ssa.Function {
+	recv := obj.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return prog.FuncValue(obj)
+	}
+
+	sel := prog.MethodSets.MethodSet(recv.Type()).Lookup(obj.Pkg(), obj.Name())
+	if sel == nil {
+		return nil
+	}
+
+	return prog.MethodValue(sel)
+}
+
 func findTargetSSAFunctions(pkgs []*ssa.Package, targetRegex *regexp.Regexp) map[functionID]*ssa.Function {
 	targetFuncs := make(map[functionID]*ssa.Function)
 	for _, ssaPkg := range pkgs {
 		for _, member := range ssaPkg.Members {
 			if fn, ok := member.(*ssa.Function); ok {
-				if targetRegex.MatchString(fn.Name()) {
+				// TestMain is not a test, it runs around all of them.
+				if fn.Name() != testMainName && targetRegex.MatchString(fn.Name()) {
 					targetFuncs[functionID{pkgPath: ssaPkg.Pkg.Path(), funcName: fn.Name()}] = fn
 				}
 			}
@@ -178,6 +178,7 @@ func TestBuildAnalysis(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotNil(t, cgs.callgraph)
 				assert.NotNil(t, cgs.targetNodes)
+				assert.NotEmpty(t, cgs.moduleDir)
 			} else {
 				assert.Error(t, err)
 				return
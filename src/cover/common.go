@@ -7,12 +7,26 @@ import (
 
 type functionID struct {
 	pkgPath  string
+	receiver string
 	funcName string
 }
 
 type analysis struct {
 	callgraph   *callgraph.Graph
 	targetNodes map[functionID]*callgraph.Node
+	// initNodes are the package initializers of the targets' packages.
+	initNodes map[functionID]*callgraph.Node
+	// setupNodes are the TestMain functions of the matched tests' packages.
+	setupNodes map[functionID]*callgraph.Node
+	moduleDir  string
+	// ignored are the lines excluded by ignore directives.
+	ignored ignoredLines
+	// generatedFiles are the module's generated files, ignored unless
+	// Options.IncludeGenerated is set.
+	generatedFiles []string
+	// filter selects the dependencies that are reported. Filtered out
+	// functions are still traversed, so that their callees are found.
+	filter filter
 }
 
 type dependency struct {
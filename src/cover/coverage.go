@@ -2,21 +2,24 @@ package cover
 
 import (
 	"fmt"
+	"go/types"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
+	profile "golang.org/x/tools/cover"
 	"golang.org/x/tools/go/ssa"
 )
 
 const mode = "set"
 
-func calculateFunctionCoverages(path, target string, dependenciesByTarget map[functionID][]dependency) ([]Coverage, error) {
+func calculateFunctionCoverages(path, target string, dependenciesByTarget map[functionID][]dependency, testFlags []string) ([]Coverage, error) {
 	dependencies := collapseDependencies(dependenciesByTarget)
 
-	coverageFile, err := runTests(path, target, dependencies)
+	coverageFile, err := runTests(path, target, dependencies, testFlags)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get coverage: %v", err)
 	}
@@ -46,7 +49,7 @@ func collapseDependencies(dependencies map[functionID][]dependency) []dependency
 	return collapsed
 }
 
-func runTests(path, target string, dependencies []dependency) (*os.File, error) {
+func runTests(path, target string, dependencies []dependency, testFlags []string) (*os.File, error) {
 	packages := make([]string, len(dependencies))
 	for i, dependency := range dependencies {
 		packages[i] = dependency.pkgPath
@@ -57,14 +60,17 @@ func runTests(path, target string, dependencies []dependency) (*os.File, error)
 		return nil, fmt.Errorf("failed to create temp file: %v", err)
 	}
 
-	cmd := exec.Command(
-		"go", "test",
+	args := []string{
+		"test",
 		"-run", target,
-		"-coverprofile="+coverageFile.Name(),
-		"-covermode="+mode,
-		"-coverpkg="+strings.Join(packages, ","),
-		path,
-	)
+		"-coverprofile=" + coverageFile.Name(),
+		"-covermode=" + mode,
+		"-coverpkg=" + strings.Join(packages, ","),
+	}
+	args = append(args, testFlags...)
+	args = append(args, path)
+
+	cmd := exec.Command("go", args...)
 	if err := cmd.Run(); err != nil {
 		os.Remove(coverageFile.Name())
 		return nil, fmt.Errorf("failed to run tests: %v", err)
@@ -82,6 +88,11 @@ func calculateFunctionCoverageFromFile(coverageFile *os.File, dependencies []dep
 		return nil, fmt.Errorf("failed to parse coverage: %v", err)
 	}
 
+	profiles, err := profile.ParseProfiles(coverageFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse coverage profile: %v", err)
+	}
+
 	rows := strings.Split(string(output), "\n")
 	coverage := []Coverage{}
 	for _, row := range rows {
@@ -94,8 +105,12 @@ func calculateFunctionCoverageFromFile(coverageFile *os.File, dependencies []dep
 		}
 
 		for _, dependency := range dependencies {
-			if strings.Contains(funcCoverage.Path, dependency.pkgPath) && funcCoverage.Name == dependency.funcName {
+			if matchesDependency(funcCoverage, dependency) {
 				funcCoverage.Statements = countFunctionStatements(dependency.ssaFunction)
+				funcCoverage.Package = dependency.pkgPath
+				funcCoverage.Receiver = functionReceiver(dependency.ssaFunction)
+				funcCoverage.File, funcCoverage.StartLine, funcCoverage.EndLine = functionExtent(dependency.ssaFunction)
+				funcCoverage.Blocks = functionBlocks(profiles, funcCoverage)
 				coverage = append(coverage, funcCoverage)
 				break
 			}
@@ -105,6 +120,23 @@ func calculateFunctionCoverageFromFile(coverageFile *os.File, dependencies []dep
 	return coverage, nil
 }
 
+// matchesDependency reports whether a go tool cover row is for the function of
+// dependency. The row's file and line are compared when the function's source
+// is known, as functions such as init and the methods of different types
+// share a name.
+func matchesDependency(funcCoverage Coverage, dependency dependency) bool {
+	if !strings.Contains(funcCoverage.Path, dependency.pkgPath) || funcCoverage.Name != dependency.funcName {
+		return false
+	}
+
+	fileName, startLine, _ := functionExtent(dependency.ssaFunction)
+	if fileName == "" {
+		return true
+	}
+
+	return strings.HasSuffix(funcCoverage.Path, fmt.Sprintf("/%s:%d:", filepath.Base(fileName), startLine))
+}
+
 var coverageRowRegex = regexp.MustCompile(`\t+`)
 
 func parseCoverageRow(row string) (Coverage, bool, error) {
@@ -154,9 +186,69 @@ func calculateTotalCoverage(coverage []Coverage) float64 {
 	return covered / total * 100
 }
 
+// countFunctionStatements counts the basic blocks of fn and its closures, as
+// go tool cover includes the closures in the coverage of fn.
 func countFunctionStatements(fn *ssa.Function) int {
 	if fn == nil {
 		return 0
 	}
-	return len(fn.Blocks)
+
+	statements := len(fn.Blocks)
+	for _, anon := range fn.AnonFuncs {
+		statements += countFunctionStatements(anon)
+	}
+
+	return statements
+}
+
+func functionExtent(fn *ssa.Function) (string, int, int) {
+	if fn == nil || fn.Prog == nil || fn.Syntax() == nil {
+		return "", 0, 0
+	}
+
+	start := fn.Prog.Fset.Position(fn.Syntax().Pos())
+	end := fn.Prog.Fset.Position(fn.Syntax().End())
+	return start.Filename, start.Line, end.Line
+}
+
+func functionReceiver(fn *ssa.Function) string {
+	if fn == nil || fn.Signature == nil || fn.Signature.Recv() == nil {
+		return ""
+	}
+
+	var qualifier types.Qualifier
+	if fn.Pkg != nil {
+		qualifier = types.RelativeTo(fn.Pkg.Pkg)
+	}
+	return types.TypeString(fn.Signature.Recv().Type(), qualifier)
+}
+
+func functionBlocks(profiles []*profile.Profile, funcCoverage Coverage) []Block {
+	if funcCoverage.StartLine == 0 {
+		return nil
+	}
+
+	fileName, _, _ := strings.Cut(funcCoverage.Path, ":")
+
+	blocks := []Block{}
+	for _, p := range profiles {
+		if p.FileName != fileName {
+			continue
+		}
+		for _, b := range p.Blocks {
+			if b.StartLine < funcCoverage.StartLine || b.EndLine > funcCoverage.EndLine {
+				continue
+			}
+			blocks = append(blocks, Block{
+				StartLine: b.StartLine,
+				StartCol:  b.StartCol,
+				EndLine:   b.EndLine,
+				EndCol:    b.EndCol,
+				NumStmt:   b.NumStmt,
+				Count:     b.Count,
+			})
+		}
+	}
+
+	return blocks
 }
@@ -1,15 +1,18 @@
 package cover
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"go/token"
+	"go/types"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	profile "golang.org/x/tools/cover"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
@@ -106,7 +109,7 @@ func TestCalculateFunctionCoverages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			coverage, err := calculateFunctionCoverages(tt.path, tt.target, tt.dependenciesByTarget)
+			coverage, err := calculateFunctionCoverages(tt.path, tt.target, tt.dependenciesByTarget, nil)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -202,6 +205,7 @@ func TestRunTests(t *testing.T) {
 		path         string
 		target       string
 		dependencies []dependency
+		testFlags    []string
 		expectError  bool
 	}{
 		{
@@ -239,11 +243,31 @@ func TestRunTests(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:   "test flags",
+			path:   getTestDataPath(),
+			target: "TestTop",
+			dependencies: []dependency{
+				{ModuleName: "github.com/leobishop234/deepcover", functionID: functionID{pkgPath: "github.com/leobishop234/deepcover/src/cover/test_data", funcName: "Top"}},
+			},
+			testFlags:   []string{"-count=1", "-tags=deepcover"},
+			expectError: false,
+		},
+		{
+			name:   "invalid test flag",
+			path:   getTestDataPath(),
+			target: "TestTop",
+			dependencies: []dependency{
+				{ModuleName: "github.com/leobishop234/deepcover", functionID: functionID{pkgPath: "github.com/leobishop234/deepcover/src/cover/test_data", funcName: "Top"}},
+			},
+			testFlags:   []string{"-notaflag"},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			coverageFile, err := runTests(tt.path, tt.target, tt.dependencies)
+			coverageFile, err := runTests(tt.path, tt.target, tt.dependencies, tt.testFlags)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -342,6 +366,36 @@ github.com/leobishop234/deepcover/src/cover/test_data/subpkg/subtest.go:14.2,16.
 	}
 }
 
+func TestMatchesDependency(t *testing.T) {
+	fn := buildSSAFunction(t, `func testFunc() {}`)
+	fileName, startLine, _ := functionExtent(fn)
+	pkgPath := fn.Pkg.Pkg.Path()
+
+	withSource := dependency{functionID: functionID{pkgPath: pkgPath, funcName: "testFunc"}, ssaFunction: fn}
+	withoutSource := dependency{functionID: functionID{pkgPath: pkgPath, funcName: "testFunc"}}
+	path := fmt.Sprintf("%s/%s:%d:", pkgPath, filepath.Base(fileName), startLine)
+
+	tests := []struct {
+		name           string
+		funcCoverage   Coverage
+		dependency     dependency
+		expectedResult bool
+	}{
+		{name: "same source", funcCoverage: Coverage{Path: path, Name: "testFunc"}, dependency: withSource, expectedResult: true},
+		{name: "other name", funcCoverage: Coverage{Path: path, Name: "init"}, dependency: withSource},
+		{name: "other line", funcCoverage: Coverage{Path: fmt.Sprintf("%s/%s:%d:", pkgPath, filepath.Base(fileName), startLine+10), Name: "testFunc"}, dependency: withSource},
+		{name: "other file", funcCoverage: Coverage{Path: fmt.Sprintf("%s/other.go:%d:", pkgPath, startLine), Name: "testFunc"}, dependency: withSource},
+		{name: "unknown source", funcCoverage: Coverage{Path: pkgPath + "/other.go:3:", Name: "testFunc"}, dependency: withoutSource, expectedResult: true},
+		{name: "other package", funcCoverage: Coverage{Path: "other/other.go:3:", Name: "testFunc"}, dependency: withoutSource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, matchesDependency(tt.funcCoverage, tt.dependency))
+		})
+	}
+}
+
 func TestParseCoverageRow(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -801,6 +855,18 @@ func TestCountExecutableStatements(t *testing.T) {
 			}`,
 			expected: 6, // actual SSA block count from test results
 		},
+		{
+			name: "function with closure",
+			code: `func testFunc() {
+				f := func(x int) {
+					if x > 0 {
+						fmt.Println(x)
+					}
+				}
+				f(1)
+			}`,
+			expected: 4, // entry block plus the closure's three blocks
+		},
 	}
 
 	for _, tt := range tests {
@@ -863,3 +929,96 @@ func buildSSAFunction(t *testing.T, code string) *ssa.Function {
 	t.Fatal("testFunc not found in SSA package")
 	return nil
 }
+
+func TestFunctionBlocks(t *testing.T) {
+	profiles, err := profile.ParseProfilesFromReader(strings.NewReader(`mode: set
+github.com/example/pkg/file.go:5.13,7.2 1 1
+github.com/example/pkg/file.go:9.16,11.2 1 0
+github.com/example/pkg/file.go:11.2,14.2 2 1
+github.com/example/pkg/other.go:9.16,11.2 1 1
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		funcCoverage Coverage
+		expected     []Block
+	}{
+		{
+			name:         "unknown function extent",
+			funcCoverage: Coverage{Path: "github.com/example/pkg/file.go:5:"},
+			expected:     nil,
+		},
+		{
+			name:         "single block",
+			funcCoverage: Coverage{Path: "github.com/example/pkg/file.go:5:", StartLine: 5, EndLine: 7},
+			expected: []Block{
+				{StartLine: 5, StartCol: 13, EndLine: 7, EndCol: 2, NumStmt: 1, Count: 1},
+			},
+		},
+		{
+			name:         "multiple blocks ignoring other files",
+			funcCoverage: Coverage{Path: "github.com/example/pkg/file.go:9:", StartLine: 9, EndLine: 14},
+			expected: []Block{
+				{StartLine: 9, StartCol: 16, EndLine: 11, EndCol: 2, NumStmt: 1, Count: 0},
+				{StartLine: 11, StartCol: 2, EndLine: 14, EndCol: 2, NumStmt: 2, Count: 1},
+			},
+		},
+		{
+			name:         "no blocks in range",
+			funcCoverage: Coverage{Path: "github.com/example/pkg/file.go:20:", StartLine: 20, EndLine: 25},
+			expected:     []Block{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, functionBlocks(profiles, tt.funcCoverage))
+		})
+	}
+}
+
+func TestFunctionReceiver(t *testing.T) {
+	pkg := types.NewPackage("github.com/example/pkg", "pkg")
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Struct", nil), types.NewStruct(nil, nil), nil)
+
+	newFunction := func(recv *types.Var) *ssa.Function {
+		return &ssa.Function{
+			Signature: types.NewSignatureType(recv, nil, nil, nil, nil, false),
+			Pkg:       &ssa.Package{Pkg: pkg},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		fn       *ssa.Function
+		expected string
+	}{
+		{
+			name:     "nil function",
+			fn:       nil,
+			expected: "",
+		},
+		{
+			name:     "plain function",
+			fn:       newFunction(nil),
+			expected: "",
+		},
+		{
+			name:     "value receiver",
+			fn:       newFunction(types.NewVar(token.NoPos, pkg, "s", named)),
+			expected: "Struct",
+		},
+		{
+			name:     "pointer receiver",
+			fn:       newFunction(types.NewVar(token.NoPos, pkg, "s", types.NewPointer(named))),
+			expected: "*Struct",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, functionReceiver(tt.fn))
+		})
+	}
+}
@@ -1,12 +1,26 @@
 package cover
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"sort"
 )
 
 type Result struct {
 	Coverage            []Coverage
 	ApproxTotalCoverage float64
+	Tests               []Test
+	ModuleDir           string
+	// TestHelpers are the functions declared in test files that the matched
+	// tests reach, when Options.TestHelpers is set. go test does not instrument
+	// test files, so their Coverage is not measured and they are left out of
+	// ApproxTotalCoverage.
+	TestHelpers []Coverage
+	// Initializers are the functions only reached through package
+	// initialization, when Options.Initializers is set. They are left out of
+	// Coverage and ApproxTotalCoverage.
+	Initializers []Coverage
 }
 
 type Coverage struct {
@@ -14,31 +28,227 @@ type Coverage struct {
 	Name       string
 	Statements int
 	Coverage   float64
+
+	Package   string
+	Receiver  string
+	File      string
+	StartLine int
+	EndLine   int
+	Blocks    []Block
+	// Tests are the matched tests that reach the function statically.
+	Tests []Function
+	// Setup reports whether the function is reached from the TestMain of a
+	// matched test's package, which runs around every test in it.
+	Setup bool
+	// TypeArguments are the instantiations of a generic function that the
+	// tests reach statically, like "[int, string]", when
+	// Options.TypeArguments is set.
+	TypeArguments []string
+}
+
+// Block is a single coverprofile block that falls within a function.
+type Block struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// Function identifies a function by its package path, receiver and name.
+type Function struct {
+	Package string
+	// Receiver is the receiver type of a method, as in Coverage.Receiver, and
+	// empty for other functions.
+	Receiver string
+	Name     string
+}
+
+// Function identifies the function c is the coverage of.
+func (c Coverage) Function() Function {
+	return Function{Package: c.Package, Receiver: c.Receiver, Name: c.Name}
+}
+
+// sortFunctions sorts fns by package, receiver and name.
+func sortFunctions(fns []Function) {
+	sort.Slice(fns, func(i, j int) bool {
+		if fns[i].Package != fns[j].Package {
+			return fns[i].Package < fns[j].Package
+		}
+		if fns[i].Receiver != fns[j].Receiver {
+			return fns[i].Receiver < fns[j].Receiver
+		}
+		return fns[i].Name < fns[j].Name
+	})
+}
+
+// Test is a matched test together with the static calls between the
+// dependencies it reaches.
+type Test struct {
+	Function
+	Calls []Call
+	// TestMain is the TestMain of the test's package, which runs around the
+	// test as shared setup, or nil if there is none. Its calls are in Calls.
+	TestMain *Function
+}
+
+type Call struct {
+	Caller Function
+	Callee Function
+}
+
+// PackageCoverage summarises the coverage of the dependencies in a package.
+type PackageCoverage struct {
+	Package    string
+	Functions  int
+	Statements int
+	Coverage   float64
 }
 
-func Deepcover(pkgPath, target string) (Result, error) {
+// Algorithms are the call graph algorithms dependencies can be found with.
+var Algorithms = []string{"cha"}
+
+// Options configure the analysis. The zero value uses the defaults.
+type Options struct {
+	// Algorithm is the call graph algorithm, one of Algorithms. Defaults to cha.
+	Algorithm string
+	// TestFlags are extra flags passed to go test, such as -tags or -race.
+	TestFlags []string
+	// Include and Exclude are regular expressions matched against
+	// "<package path>.<function name>", with "(*T)." or "T." before the name
+	// of a method, or globs when prefixed with "glob:". Globs are matched path
+	// segment by segment against the end of the name, and "**" matches any
+	// number of segments. If Include is not empty only matching dependencies
+	// are reported, and those matching Exclude never are.
+	Include []string
+	Exclude []string
+	// IncludeGenerated reports functions in files with a "Code generated ...
+	// DO NOT EDIT." header, which are ignored by default.
+	IncludeGenerated bool
+	// TestHelpers reports the functions declared in test files that the
+	// matched tests reach in Result.TestHelpers.
+	TestHelpers bool
+	// TypeArguments reports the instantiations of each generic function in
+	// Coverage.TypeArguments.
+	TypeArguments bool
+	// Initializers reports the functions only reached through the package
+	// initialization that tests run implicitly in Result.Initializers, rather
+	// than in Result.Coverage.
+	Initializers bool
+}
+
+func Deepcover(pkgPath, target string, options Options) (Result, error) {
+	if options.Algorithm != "" && !slices.Contains(Algorithms, options.Algorithm) {
+		return Result{}, fmt.Errorf("unsupported algorithm %q", options.Algorithm)
+	}
+
 	targetRegex, err := regexp.Compile(target)
 	if err != nil {
 		return Result{}, err
 	}
 
+	dependencyFilter, err := newFilter(options.Include, options.Exclude)
+	if err != nil {
+		return Result{}, err
+	}
+
 	cgs, err := buildAnalysis(pkgPath, targetRegex)
 	if err != nil {
 		return Result{}, err
 	}
+	if !options.IncludeGenerated {
+		cgs.ignored = cgs.ignored.withFiles(cgs.generatedFiles)
+	}
+	dependencyFilter.ignored = cgs.ignored
+	cgs.filter = dependencyFilter
 
 	dependencies, err := getDependencies(cgs)
 	if err != nil {
 		return Result{}, err
 	}
 
-	coverage, err := calculateFunctionCoverages(pkgPath, target, dependencies)
+	setupDependencies, err := getSetupDependencies(cgs)
 	if err != nil {
 		return Result{}, err
 	}
+	dependencies = mergeDependencies(dependencies, setupDependencies)
 
-	return Result{
+	initDependencies, err := getInitDependencies(cgs)
+	if err != nil {
+		return Result{}, err
+	}
+
+	production, helpers := separateTestHelpers(dependencies)
+	initProduction, _ := separateTestHelpers(initDependencies)
+
+	coverage, err := calculateFunctionCoverages(pkgPath, target, mergeDependencies(production, initProduction), options.TestFlags)
+	if err != nil {
+		return Result{}, err
+	}
+
+	coverage = ignoreBlocks(coverage, cgs.ignored)
+
+	tests := collectTests(dependencies, cgs.filter)
+	attributeTestMains(tests, cgs.setupNodes)
+	attributeTests(coverage, tests)
+	attributeInitializers(coverage, initProduction)
+	attributeSetup(coverage, setupDependencies)
+	if options.TypeArguments {
+		attributeTypeArguments(coverage, mergeDependencies(dependencies, initDependencies))
+	}
+
+	var initializers []Coverage
+	if options.Initializers {
+		coverage, initializers = separateInitializers(coverage, production)
+	}
+
+	result := Result{
 		Coverage:            coverage,
 		ApproxTotalCoverage: calculateTotalCoverage(coverage),
-	}, nil
+		Tests:               tests,
+		ModuleDir:           cgs.moduleDir,
+		Initializers:        initializers,
+	}
+
+	if options.TestHelpers {
+		result.TestHelpers = testHelperCoverage(helpers)
+		attributeTests(result.TestHelpers, tests)
+		attributeSetup(result.TestHelpers, setupDependencies)
+	}
+
+	return result, nil
+}
+
+// TotalCoverage is the statement weighted coverage of the given functions.
+func TotalCoverage(coverage []Coverage) float64 {
+	return calculateTotalCoverage(coverage)
+}
+
+func Packages(coverage []Coverage) []PackageCoverage {
+	byPackage := map[string][]Coverage{}
+	for _, c := range coverage {
+		byPackage[c.Package] = append(byPackage[c.Package], c)
+	}
+
+	packages := make([]PackageCoverage, 0, len(byPackage))
+	for pkg, pkgCoverage := range byPackage {
+		statements := 0
+		for _, c := range pkgCoverage {
+			statements += c.Statements
+		}
+
+		packages = append(packages, PackageCoverage{
+			Package:    pkg,
+			Functions:  len(pkgCoverage),
+			Statements: statements,
+			Coverage:   calculateTotalCoverage(pkgCoverage),
+		})
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Package < packages[j].Package
+	})
+
+	return packages
 }
@@ -0,0 +1,44 @@
+package cover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackages(t *testing.T) {
+	tests := []struct {
+		name     string
+		coverage []Coverage
+		expected []PackageCoverage
+	}{
+		{
+			name:     "empty coverage",
+			coverage: []Coverage{},
+			expected: []PackageCoverage{},
+		},
+		{
+			name: "multiple packages",
+			coverage: []Coverage{
+				{Package: "pkg2", Name: "Func3", Statements: 4, Coverage: 25},
+				{Package: "pkg1", Name: "Func1", Statements: 10, Coverage: 100},
+				{Package: "pkg1", Name: "Func2", Statements: 10, Coverage: 50},
+			},
+			expected: []PackageCoverage{
+				{Package: "pkg1", Functions: 2, Statements: 20, Coverage: 75},
+				{Package: "pkg2", Functions: 1, Statements: 4, Coverage: 25},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Packages(tt.coverage))
+		})
+	}
+}
+
+func TestDeepcoverUnsupportedAlgorithm(t *testing.T) {
+	_, err := Deepcover("./test_data", "Test", Options{Algorithm: "rta"})
+	assert.EqualError(t, err, `unsupported algorithm "rta"`)
+}
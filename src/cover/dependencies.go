@@ -2,16 +2,27 @@ package cover
 
 import (
 	"fmt"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
 )
 
 func getDependencies(cgs analysis) (map[functionID][]dependency, error) {
-	dependencies := make(map[functionID][]dependency, len(cgs.targetNodes))
+	return dependenciesFrom(cgs, cgs.targetNodes)
+}
+
+// dependenciesFrom returns the dependencies reached from each of nodes, keyed
+// like nodes.
+func dependenciesFrom(cgs analysis, nodes map[functionID]*callgraph.Node) (map[functionID][]dependency, error) {
+	dependencies := make(map[functionID][]dependency, len(nodes))
 	var err error
-	for targetID, targetNode := range cgs.targetNodes {
-		dependencies[targetID], err = extractDependencies(cgs, targetNode)
+	for targetID, node := range nodes {
+		dependencies[targetID], err = extractDependencies(cgs, node)
 		if err != nil {
 			return nil, err
 		}
@@ -45,26 +56,27 @@ func extractDependencies(cg analysis, start *callgraph.Node) ([]dependency, erro
 			continue
 		}
 
-		module, hasModule, err := getNodeModule(current)
-		if err != nil {
-			return nil, err
-		}
-		if !hasModule {
-			continue
-		}
-		if module != rootModule {
-			continue
-		}
+		if !isSynthetic(current.Func) {
+			module, hasModule, err := getNodeModule(current)
+			if err != nil {
+				return nil, err
+			}
+			if !hasModule {
+				continue
+			}
+			if module != rootModule {
+				continue
+			}
 
-		dependencies = append(dependencies, dependency{
-			ModuleName: module,
-			functionID: functionID{
-				pkgPath:  current.Func.Pkg.Pkg.Path(),
-				funcName: current.Func.Name(),
-			},
-			ssaFunction: current.Func,
-			node:        current,
-		})
+			if cg.filter.reports(current) {
+				dependencies = append(dependencies, dependency{
+					ModuleName:  module,
+					functionID:  nodeID(current),
+					ssaFunction: declaredFunction(current.Func),
+					node:        current,
+				})
+			}
+		}
 
 		for _, edge := range current.Out {
 			if !visited[edge.Callee] {
@@ -78,6 +90,321 @@ func extractDependencies(cg analysis, start *callgraph.Node) ([]dependency, erro
 	return dependencies, nil
 }
 
+// collectTests returns the calls between the dependencies of each target.
+// Calls through functions that f does not report are attributed to their
+// caller, so that the functions reached through them are still connected.
+// Calls made by closures, including range-over-func loop bodies, are
+// attributed to the function enclosing them, and calls to closures are left
+// out.
+func collectTests(dependenciesByTarget map[functionID][]dependency, f filter) []Test {
+	tests := make([]Test, 0, len(dependenciesByTarget))
+	for targetID, deps := range dependenciesByTarget {
+		reached := make(map[*callgraph.Node]bool, len(deps))
+		for _, dep := range deps {
+			if dep.node != nil {
+				reached[dep.node] = true
+			}
+		}
+
+		seen := map[Call]bool{}
+		calls := []Call{}
+		for _, dep := range deps {
+			if dep.node == nil {
+				continue
+			}
+
+			caller := nodeFunction(dep.node)
+			for _, callee := range reachedCallees(dep.node, reached, f) {
+				if callee.Func.Parent() != nil {
+					// A closure is part of the function enclosing it, which
+					// is connected by its own calls.
+					continue
+				}
+
+				call := Call{Caller: caller, Callee: nodeFunction(callee)}
+				if call.Caller == call.Callee && dep.node.Func.Synthetic != "" {
+					// A wrapper calling the method it wraps.
+					continue
+				}
+				if !seen[call] {
+					seen[call] = true
+					calls = append(calls, call)
+				}
+			}
+		}
+
+		tests = append(tests, Test{
+			Function: Function{Package: targetID.pkgPath, Receiver: targetID.receiver, Name: targetID.funcName},
+			Calls:    calls,
+		})
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		if tests[i].Package != tests[j].Package {
+			return tests[i].Package < tests[j].Package
+		}
+		return tests[i].Name < tests[j].Name
+	})
+
+	return tests
+}
+
+// reachedCallees returns the callees of node that are in reached, looking
+// through callees that f does not report.
+func reachedCallees(node *callgraph.Node, reached map[*callgraph.Node]bool, f filter) []*callgraph.Node {
+	callees := []*callgraph.Node{}
+	visited := map[*callgraph.Node]bool{node: true}
+
+	var visit func(n *callgraph.Node)
+	visit = func(n *callgraph.Node) {
+		for _, edge := range n.Out {
+			callee := edge.Callee
+			if visited[callee] {
+				continue
+			}
+			visited[callee] = true
+
+			switch {
+			case reached[callee]:
+				callees = append(callees, callee)
+			case callee.Func != nil && !f.reports(callee):
+				visit(callee)
+			}
+		}
+	}
+	visit(node)
+
+	return callees
+}
+
+// separateTestHelpers splits the dependencies of each target into those
+// declared in the module's packages and the test helpers, functions other than
+// the targets that are declared in test files or in the generated test main
+// package.
+func separateTestHelpers(dependenciesByTarget map[functionID][]dependency) (map[functionID][]dependency, []dependency) {
+	production := make(map[functionID][]dependency, len(dependenciesByTarget))
+	seen := map[functionID]bool{}
+	helpers := []dependency{}
+	for targetID, deps := range dependenciesByTarget {
+		production[targetID] = []dependency{}
+		for _, dep := range deps {
+			switch {
+			case !isTestHelper(dep.ssaFunction):
+				production[targetID] = append(production[targetID], dep)
+			case dep.functionID != targetID && !seen[dep.functionID]:
+				seen[dep.functionID] = true
+				helpers = append(helpers, dep)
+			}
+		}
+	}
+
+	sort.Slice(helpers, func(i, j int) bool {
+		if helpers[i].pkgPath != helpers[j].pkgPath {
+			return helpers[i].pkgPath < helpers[j].pkgPath
+		}
+		if helpers[i].funcName != helpers[j].funcName {
+			return helpers[i].funcName < helpers[j].funcName
+		}
+		return helpers[i].receiver < helpers[j].receiver
+	})
+
+	return production, helpers
+}
+
+// isTestHelper reports whether fn is declared in a test file or in the
+// generated test main package.
+func isTestHelper(fn *ssa.Function) bool {
+	if fn == nil {
+		return false
+	}
+	if fn.Pkg != nil && strings.HasSuffix(fn.Pkg.Pkg.Path(), ".test") {
+		return true
+	}
+
+	fileName, _, _ := functionExtent(fn)
+	return strings.HasSuffix(fileName, "_test.go")
+}
+
+// testHelperCoverage describes the test helpers declared in source files,
+// leaving their coverage unmeasured.
+func testHelperCoverage(helpers []dependency) []Coverage {
+	coverage := []Coverage{}
+	for _, helper := range helpers {
+		fileName, startLine, endLine := functionExtent(helper.ssaFunction)
+		if fileName == "" {
+			continue
+		}
+
+		dir := strings.TrimSuffix(helper.pkgPath, "_test")
+		coverage = append(coverage, Coverage{
+			Path:       fmt.Sprintf("%s/%s:%d:", dir, filepath.Base(fileName), startLine),
+			Name:       helper.funcName,
+			Statements: countFunctionStatements(helper.ssaFunction),
+			Package:    helper.pkgPath,
+			Receiver:   functionReceiver(helper.ssaFunction),
+			File:       fileName,
+			StartLine:  startLine,
+			EndLine:    endLine,
+		})
+	}
+
+	return coverage
+}
+
+func attributeTests(coverage []Coverage, tests []Test) {
+	reachedBy := map[Function][]Function{}
+	for _, test := range tests {
+		reached := map[Function]bool{test.Function: true}
+		for _, call := range test.Calls {
+			reached[call.Caller] = true
+			reached[call.Callee] = true
+		}
+
+		for fn := range reached {
+			reachedBy[fn] = append(reachedBy[fn], test.Function)
+		}
+	}
+
+	for i := range coverage {
+		tests := reachedBy[coverage[i].Function()]
+		sortFunctions(tests)
+		coverage[i].Tests = tests
+	}
+}
+
+// attributeTypeArguments sets the type arguments each generic function in
+// coverage is instantiated with by the dependencies.
+func attributeTypeArguments(coverage []Coverage, dependenciesByTarget map[functionID][]dependency) {
+	instances := map[functionID]map[string]bool{}
+	for _, deps := range dependenciesByTarget {
+		for _, dep := range deps {
+			if dep.node == nil {
+				continue
+			}
+
+			if args := typeArguments(dep.node.Func); args != "" {
+				if instances[dep.functionID] == nil {
+					instances[dep.functionID] = map[string]bool{}
+				}
+				instances[dep.functionID][args] = true
+			}
+		}
+	}
+
+	for i := range coverage {
+		args := instances[coverageID(coverage[i])]
+		if len(args) == 0 {
+			continue
+		}
+
+		coverage[i].TypeArguments = make([]string, 0, len(args))
+		for arg := range args {
+			coverage[i].TypeArguments = append(coverage[i].TypeArguments, arg)
+		}
+		sort.Strings(coverage[i].TypeArguments)
+	}
+}
+
+func nodeID(node *callgraph.Node) functionID {
+	fn := declaredFunction(node.Func)
+	return functionID{
+		pkgPath:  fn.Pkg.Pkg.Path(),
+		receiver: functionReceiver(fn),
+		funcName: functionName(fn),
+	}
+}
+
+// coverageID identifies the function c is the coverage of, like nodeID.
+func coverageID(c Coverage) functionID {
+	return functionID{pkgPath: c.Package, receiver: c.Receiver, funcName: c.Name}
+}
+
+func nodeFunction(node *callgraph.Node) Function {
+	fn := declaredFunction(node.Func)
+	return Function{
+		Package:  fn.Pkg.Pkg.Path(),
+		Receiver: functionReceiver(fn),
+		Name:     functionName(fn),
+	}
+}
+
+// functionName returns the name of fn as go tool cover reports it, without
+// the "#n" suffix SSA gives each init function of a package.
+func functionName(fn *ssa.Function) string {
+	name, _, _ := strings.Cut(fn.Name(), "#")
+	return name
+}
+
+// declaredFunction returns the top-level function fn is declared as, so that
+// closures, including the bodies of range-over-func loops, are reported as
+// part of the function containing them, instantiations of a generic function
+// as the generic function and synthetic wrappers as the method they wrap.
+func declaredFunction(fn *ssa.Function) *ssa.Function {
+	for {
+		switch {
+		case fn.Parent() != nil:
+			fn = fn.Parent()
+		case fn.Origin() != nil:
+			fn = fn.Origin()
+		case wrappedMethod(fn) != nil:
+			fn = wrappedMethod(fn)
+		default:
+			return fn
+		}
+	}
+}
+
+// wrappedMethod returns the declared method that a synthetic wrapper, such as
+// a bound method closure or a promoted method wrapper, calls, or nil if fn
+// does not wrap one.
+func wrappedMethod(fn *ssa.Function) *ssa.Function {
+	if fn.Synthetic == "" || fn.Prog == nil {
+		return nil
+	}
+
+	method, ok := fn.Object().(*types.Func)
+	if !ok {
+		return nil
+	}
+
+	wrapped := funcValue(fn.Prog, method)
+	if wrapped == fn {
+		return nil
+	}
+
+	return wrapped
+}
+
+// isSynthetic reports whether fn has no source of its own and wraps no
+// declared method, like interface method thunks and package initializers.
+// These are traversed but never reported.
+func isSynthetic(fn *ssa.Function) bool {
+	return fn != nil && declaredFunction(fn).Synthetic != ""
+}
+
+// typeArguments returns the type arguments fn was instantiated with, formatted
+// like "[int, string]", or an empty string if fn is not an instantiation.
+func typeArguments(fn *ssa.Function) string {
+	for fn.Parent() != nil {
+		fn = fn.Parent()
+	}
+	if fn.Origin() == nil {
+		return ""
+	}
+
+	var qualifier types.Qualifier
+	if pkg := declaredFunction(fn).Pkg; pkg != nil {
+		qualifier = types.RelativeTo(pkg.Pkg)
+	}
+
+	args := make([]string, len(fn.TypeArgs()))
+	for i, arg := range fn.TypeArgs() {
+		args[i] = types.TypeString(arg, qualifier)
+	}
+
+	return "[" + strings.Join(args, ", ") + "]"
+}
+
 type knownPackage struct {
 	hasModule bool
 	module    string
@@ -86,18 +413,25 @@ type knownPackage struct {
 var knownPackages = map[string]knownPackage{}
 
 func getNodeModule(node *callgraph.Node) (string, bool, error) {
-	if node == nil || node.Func == nil || node.Func.Pkg == nil || node.Func.Pkg.Pkg == nil {
+	if node == nil || node.Func == nil {
+		return "", false, nil
+	}
+
+	fn := declaredFunction(node.Func)
+	if fn.Pkg == nil || fn.Pkg.Pkg == nil {
 		return "", false, nil
 	}
 
-	pkgPath := node.Func.Pkg.Pkg.Path()
+	pkgPath := fn.Pkg.Pkg.Path()
 	if known, ok := knownPackages[pkgPath]; ok {
 		return known.module, known.hasModule, nil
 	}
 
+	// External test packages cannot be loaded by path, but share the module
+	// of the package they test.
 	pkgs, err := packages.Load(&packages.Config{
 		Mode: packages.NeedName | packages.NeedModule,
-	}, pkgPath)
+	}, packageUnderTest(pkgPath))
 	if err != nil {
 		return "", false, err
 	}
@@ -1,12 +1,17 @@
 package cover
 
 import (
-	"testing"
-
+	"go/token"
 	"go/types"
+	"os"
+	"strings"
+	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -17,6 +22,50 @@ func TestExtractDependencies(t *testing.T) {
 		expectedDeps   []dependency
 		expectedError  bool
 	}{
+		{
+			name: "excluded function is traversed but not reported",
+			setupCallGraph: func() analysis {
+				knownPackages = map[string]knownPackage{}
+				newNode := func(pkgPath string) *callgraph.Node {
+					knownPackages[pkgPath] = knownPackage{hasModule: true, module: "github.com/leobishop234/deepcover"}
+					fn := &ssa.Function{}
+					fn.Pkg = &ssa.Package{Pkg: types.NewPackage(pkgPath, "pkg")}
+					return &callgraph.Node{Func: fn}
+				}
+
+				root := newNode("github.com/leobishop234/deepcover/pkg")
+				mock := newNode("github.com/leobishop234/deepcover/pkg/mocks")
+				real := newNode("github.com/leobishop234/deepcover/pkg/real")
+				for _, edge := range []*callgraph.Edge{
+					{Caller: root, Callee: mock},
+					{Caller: mock, Callee: real},
+				} {
+					edge.Caller.Out = append(edge.Caller.Out, edge)
+					edge.Callee.In = append(edge.Callee.In, edge)
+				}
+
+				f, err := newFilter(nil, []string{"/mocks\\."})
+				if err != nil {
+					panic(err)
+				}
+
+				return analysis{
+					callgraph: &callgraph.Graph{Root: root},
+					filter:    f,
+				}
+			},
+			expectedDeps: []dependency{
+				{
+					ModuleName: "github.com/leobishop234/deepcover",
+					functionID: functionID{pkgPath: "github.com/leobishop234/deepcover/pkg"},
+				},
+				{
+					ModuleName: "github.com/leobishop234/deepcover",
+					functionID: functionID{pkgPath: "github.com/leobishop234/deepcover/pkg/real"},
+				},
+			},
+			expectedError: false,
+		},
 		{
 			name: "call graph with nil root",
 			setupCallGraph: func() analysis {
@@ -274,3 +323,399 @@ func TestGetNodeModule(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectTests(t *testing.T) {
+	pkg := types.NewPackage("github.com/leobishop234/deepcover/src/cover/test_data", "test_data")
+	ssaPkg := &ssa.Package{Pkg: pkg}
+
+	newNode := func(name string) *callgraph.Node {
+		fn := &ssa.Function{}
+		fn.Pkg = ssaPkg
+		return &callgraph.Node{Func: fn}
+	}
+	root := newNode("TestTop")
+	called := newNode("Top")
+	external := newNode("Atoi")
+
+	for _, edge := range []*callgraph.Edge{
+		{Caller: root, Callee: called},
+		{Caller: root, Callee: called},
+		{Caller: called, Callee: external},
+	} {
+		edge.Caller.Out = append(edge.Caller.Out, edge)
+		edge.Callee.In = append(edge.Callee.In, edge)
+	}
+
+	targetID := functionID{pkgPath: pkg.Path(), funcName: "TestTop"}
+	tests := collectTests(map[functionID][]dependency{
+		targetID: {
+			{functionID: targetID, node: root},
+			{functionID: functionID{pkgPath: pkg.Path(), funcName: ""}, node: called},
+		},
+	}, filter{})
+
+	// Name() returns an empty string for empty ssa.Functions
+	fn := Function{Package: pkg.Path(), Name: ""}
+	assert.Equal(t, []Test{
+		{
+			Function: Function{Package: pkg.Path(), Name: "TestTop"},
+			Calls:    []Call{{Caller: fn, Callee: fn}},
+		},
+	}, tests)
+}
+
+func TestCollectTestsThroughExcludedFunctions(t *testing.T) {
+	newNode := func(pkgPath string) *callgraph.Node {
+		fn := &ssa.Function{}
+		fn.Pkg = &ssa.Package{Pkg: types.NewPackage(pkgPath, "pkg")}
+		return &callgraph.Node{Func: fn}
+	}
+	root := newNode("pkg")
+	mock := newNode("pkg/mocks")
+	nested := newNode("pkg/mocks/nested")
+	real := newNode("pkg/real")
+
+	for _, edge := range []*callgraph.Edge{
+		{Caller: root, Callee: mock},
+		{Caller: mock, Callee: nested},
+		{Caller: nested, Callee: real},
+		{Caller: nested, Callee: mock},
+	} {
+		edge.Caller.Out = append(edge.Caller.Out, edge)
+		edge.Callee.In = append(edge.Callee.In, edge)
+	}
+
+	f, err := newFilter(nil, []string{"/mocks"})
+	assert.NoError(t, err)
+
+	targetID := functionID{pkgPath: "pkg", funcName: "TestTop"}
+	tests := collectTests(map[functionID][]dependency{
+		targetID: {
+			{functionID: targetID, node: root},
+			{functionID: functionID{pkgPath: "pkg/real"}, node: real},
+		},
+	}, f)
+
+	assert.Equal(t, []Test{
+		{
+			Function: Function{Package: "pkg", Name: "TestTop"},
+			Calls:    []Call{{Caller: Function{Package: "pkg"}, Callee: Function{Package: "pkg/real"}}},
+		},
+	}, tests)
+}
+
+func TestEnclosingFunction(t *testing.T) {
+	fn := buildSSAFunction(t, `func testFunc() {
+		f := func() {
+			g := func() {}
+			g()
+		}
+		f()
+	}`)
+	require.Len(t, fn.AnonFuncs, 1)
+	require.Len(t, fn.AnonFuncs[0].AnonFuncs, 1)
+
+	assert.Equal(t, fn, declaredFunction(fn))
+	assert.Equal(t, fn, declaredFunction(fn.AnonFuncs[0]))
+	assert.Equal(t, fn, declaredFunction(fn.AnonFuncs[0].AnonFuncs[0]))
+}
+
+func TestFunctionName(t *testing.T) {
+	fn := buildSSAFunction(t, `var registry = map[string]int{}
+
+	func init() { registry["a"] = 1 }
+
+	func init() { registry["b"] = 2 }
+
+	func testFunc() {}`)
+
+	inits := []string{}
+	for _, member := range fn.Pkg.Members {
+		if member, ok := member.(*ssa.Function); ok && strings.HasPrefix(member.Name(), "init#") {
+			inits = append(inits, functionName(member))
+		}
+	}
+
+	assert.Equal(t, []string{"init", "init"}, inits)
+	assert.Equal(t, "init", functionName(fn.Pkg.Func("init")))
+	assert.Equal(t, "testFunc", functionName(fn))
+}
+
+func TestGenericInstances(t *testing.T) {
+	fn := buildSSAFunction(t, `func testFunc() {
+		_ = id(1)
+		_ = id("a")
+		_ = id(struct{}{})
+	}
+
+	func id[T any](v T) T {
+		f := func() T { return v }
+		return f()
+	}`)
+
+	instances := []*ssa.Function{}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			if call, ok := instr.(*ssa.Call); ok && call.Call.StaticCallee() != nil {
+				instances = append(instances, call.Call.StaticCallee())
+			}
+		}
+	}
+	require.Len(t, instances, 3)
+
+	origin := fn.Pkg.Func("id")
+	for _, instance := range instances {
+		assert.Equal(t, origin, declaredFunction(instance))
+	}
+	assert.Equal(t, origin, declaredFunction(origin.AnonFuncs[0]))
+
+	assert.Equal(t, "[int]", typeArguments(instances[0]))
+	assert.Equal(t, "[string]", typeArguments(instances[1]))
+	assert.Equal(t, "[struct{}]", typeArguments(instances[2]))
+	assert.Equal(t, "", typeArguments(origin))
+	assert.Equal(t, "", typeArguments(fn))
+
+	pkgPath := fn.Pkg.Pkg.Path()
+	idID := functionID{pkgPath: pkgPath, funcName: "id"}
+	assert.Equal(t, idID, nodeID(&callgraph.Node{Func: instances[0]}))
+
+	coverage := []Coverage{
+		{Package: pkgPath, Name: "id"},
+		{Package: pkgPath, Name: "testFunc"},
+	}
+	targetID := functionID{pkgPath: pkgPath, funcName: "TestID"}
+	attributeTypeArguments(coverage, map[functionID][]dependency{
+		targetID: {
+			{functionID: functionID{pkgPath: pkgPath, funcName: "testFunc"}, node: &callgraph.Node{Func: fn}},
+			{functionID: idID, node: &callgraph.Node{Func: instances[1]}},
+			{functionID: idID, node: &callgraph.Node{Func: instances[0]}},
+		},
+		{pkgPath: pkgPath, funcName: "TestOther"}: {
+			{functionID: idID, node: &callgraph.Node{Func: instances[0]}},
+		},
+	})
+
+	assert.Equal(t, []string{"[int]", "[string]"}, coverage[0].TypeArguments)
+	assert.Nil(t, coverage[1].TypeArguments)
+}
+
+func TestSyntheticFunctions(t *testing.T) {
+	fn := buildSSAFunction(t, `func testFunc() {
+		var b Base
+		bound := b.Name
+		thunk := Namer.Name
+		_, _ = bound(), thunk(b)
+	}
+
+	type Base struct{}
+
+	func (Base) Name() string { return "base" }
+
+	type Outer struct{ Base }
+
+	type Namer interface{ Name() string }`)
+
+	var bound, thunk *ssa.Function
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			for _, operand := range instr.Operands(nil) {
+				if f, ok := (*operand).(*ssa.Function); ok {
+					switch {
+					case strings.HasSuffix(f.Name(), "$bound"):
+						bound = f
+					case strings.HasSuffix(f.Name(), "$thunk"):
+						thunk = f
+					}
+				}
+			}
+		}
+	}
+	require.NotNil(t, bound)
+	require.NotNil(t, thunk)
+
+	prog := fn.Prog
+	pkg := fn.Pkg.Pkg
+	outer := pkg.Scope().Lookup("Outer").Type()
+	promoted := prog.MethodValue(prog.MethodSets.MethodSet(types.NewPointer(outer)).Lookup(pkg, "Name"))
+	require.NotNil(t, promoted)
+	require.NotEmpty(t, promoted.Synthetic)
+
+	name := funcValue(prog, pkg.Scope().Lookup("Base").Type().(*types.Named).Method(0))
+	require.NotNil(t, name)
+	require.Empty(t, name.Synthetic)
+
+	tests := []struct {
+		name             string
+		fn               *ssa.Function
+		expectedDeclared *ssa.Function
+		expectedReported bool
+	}{
+		{name: "declared method", fn: name, expectedDeclared: name, expectedReported: true},
+		{name: "bound method closure", fn: bound, expectedDeclared: name, expectedReported: true},
+		{name: "promoted method wrapper", fn: promoted, expectedDeclared: name, expectedReported: true},
+		{name: "interface method thunk", fn: thunk, expectedDeclared: thunk, expectedReported: false},
+		{name: "package initializer", fn: fn.Pkg.Func("init"), expectedDeclared: fn.Pkg.Func("init"), expectedReported: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedDeclared, declaredFunction(tt.fn))
+			assert.Equal(t, !tt.expectedReported, isSynthetic(tt.fn))
+			assert.Equal(t, tt.expectedReported, filter{}.reports(&callgraph.Node{Func: tt.fn}))
+		})
+	}
+
+	node := &callgraph.Node{Func: promoted}
+	assert.Equal(t, functionID{pkgPath: pkg.Path(), receiver: "Base", funcName: "Name"}, nodeID(node))
+	assert.Equal(t, Function{Package: pkg.Path(), Receiver: "Base", Name: "Name"}, nodeFunction(node))
+}
+
+// TestRangeOverFuncBodies loads a module of its own, as range-over-func loops
+// need a newer go version than this module declares.
+func TestRangeOverFuncBodies(t *testing.T) {
+	pkgs, err := loadPackages(&packages.Config{
+		Mode: packages.LoadSyntax | packages.NeedDeps | packages.NeedModule,
+		Dir:  "test_data/rangefunc",
+		// Load the module with its own go.mod, whatever the flags used to
+		// build this one.
+		Env:  append(os.Environ(), "GOFLAGS="),
+		Fset: token.NewFileSet(),
+	}, ".")
+	require.NoError(t, err)
+	require.Equal(t, "example.com/rangefunc", pkgs[0].PkgPath)
+
+	prog, ssaPkgs, err := buildSSAObjects(pkgs)
+	require.NoError(t, err)
+	pkg := ssaPkgs[0]
+	sum := pkg.Func("Sum")
+	require.Len(t, sum.AnonFuncs, 1)
+	body := sum.AnonFuncs[0]
+
+	assert.Equal(t, sum, declaredFunction(body))
+	assert.Equal(t, len(sum.Blocks)+len(body.Blocks), countFunctionStatements(sum))
+
+	graph := cha.CallGraph(prog)
+	deps := []dependency{}
+	for fn, node := range graph.Nodes {
+		if fn != nil && declaredFunction(fn).Pkg == pkg && !isSynthetic(fn) {
+			deps = append(deps, dependency{functionID: nodeID(node), ssaFunction: declaredFunction(fn), node: node})
+		}
+	}
+
+	pkgPath := pkg.Pkg.Path()
+	targetID := functionID{pkgPath: pkgPath, funcName: "Sum"}
+	tests := collectTests(map[functionID][]dependency{targetID: deps}, filter{})
+	require.Len(t, tests, 1)
+	assert.ElementsMatch(t, []Call{
+		{Caller: Function{Package: pkgPath, Name: "Sum"}, Callee: Function{Package: pkgPath, Name: "Numbers"}},
+		{Caller: Function{Package: pkgPath, Name: "Sum"}, Callee: Function{Package: pkgPath, Name: "double"}},
+	}, tests[0].Calls)
+}
+
+func TestCollectTestsClosures(t *testing.T) {
+	fn := buildSSAFunction(t, `func testFunc() {
+		f := func() { helper() }
+		f()
+	}
+
+	func helper() {}`)
+	require.Len(t, fn.AnonFuncs, 1)
+
+	parent := &callgraph.Node{Func: fn}
+	closure := &callgraph.Node{Func: fn.AnonFuncs[0]}
+	helper := &callgraph.Node{Func: fn.Pkg.Func("helper")}
+	for _, edge := range []*callgraph.Edge{
+		{Caller: parent, Callee: closure},
+		{Caller: closure, Callee: helper},
+		{Caller: helper, Callee: closure},
+	} {
+		edge.Caller.Out = append(edge.Caller.Out, edge)
+		edge.Callee.In = append(edge.Callee.In, edge)
+	}
+
+	pkgPath := fn.Pkg.Pkg.Path()
+	targetID := functionID{pkgPath: pkgPath, funcName: "testFunc"}
+	tests := collectTests(map[functionID][]dependency{
+		targetID: {
+			{functionID: targetID, node: parent},
+			{functionID: targetID, node: closure},
+			{functionID: functionID{pkgPath: pkgPath, funcName: "helper"}, node: helper},
+		},
+	}, filter{})
+
+	assert.Equal(t, []Test{
+		{
+			Function: Function{Package: pkgPath, Name: "testFunc"},
+			Calls: []Call{
+				{Caller: Function{Package: pkgPath, Name: "testFunc"}, Callee: Function{Package: pkgPath, Name: "helper"}},
+			},
+		},
+	}, tests)
+}
+
+func TestSeparateTestHelpers(t *testing.T) {
+	newFunction := func(pkgPath string) *ssa.Function {
+		fn := &ssa.Function{}
+		fn.Pkg = &ssa.Package{Pkg: types.NewPackage(pkgPath, "pkg")}
+		return fn
+	}
+	targetFn := newFunction("pkg.test")
+	helperFn := newFunction("pkg.test")
+	productionFn := newFunction("pkg")
+
+	targetID := functionID{pkgPath: "pkg.test", funcName: "TestTop"}
+	target := dependency{functionID: targetID, ssaFunction: targetFn}
+	helper := dependency{functionID: functionID{pkgPath: "pkg.test", funcName: "helper"}, ssaFunction: helperFn}
+	production := dependency{functionID: functionID{pkgPath: "pkg", funcName: "Top"}, ssaFunction: productionFn}
+	otherID := functionID{pkgPath: "pkg.test", funcName: "TestOther"}
+
+	productionDeps, helpers := separateTestHelpers(map[functionID][]dependency{
+		targetID: {target, helper, production},
+		otherID:  {helper},
+	})
+
+	assert.Equal(t, map[functionID][]dependency{
+		targetID: {production},
+		otherID:  {},
+	}, productionDeps)
+	assert.Equal(t, []dependency{helper}, helpers)
+	assert.Equal(t, []Coverage{}, testHelperCoverage(helpers))
+}
+
+func TestAttributeTests(t *testing.T) {
+	top := Function{Package: "pkg", Name: "Top"}
+	bottom := Function{Package: "pkg", Name: "Bottom"}
+	sub := Function{Package: "pkg/sub", Name: "Sub"}
+
+	coverage := []Coverage{
+		{Package: "pkg", Name: "Top"},
+		{Package: "pkg", Name: "Bottom"},
+		{Package: "pkg/sub", Name: "Sub"},
+		{Package: "pkg", Name: "Unreached"},
+	}
+
+	attributeTests(coverage, []Test{
+		{
+			Function: Function{Package: "pkg", Name: "TestTop"},
+			Calls: []Call{
+				{Caller: Function{Package: "pkg", Name: "TestTop"}, Callee: top},
+				{Caller: top, Callee: bottom},
+				{Caller: bottom, Callee: sub},
+			},
+		},
+		{
+			Function: Function{Package: "pkg", Name: "TestBottom"},
+			Calls: []Call{
+				{Caller: Function{Package: "pkg", Name: "TestBottom"}, Callee: bottom},
+				{Caller: bottom, Callee: sub},
+			},
+		},
+	})
+
+	testTop := Function{Package: "pkg", Name: "TestTop"}
+	testBottom := Function{Package: "pkg", Name: "TestBottom"}
+	assert.Equal(t, []Function{testTop}, coverage[0].Tests)
+	assert.Equal(t, []Function{testBottom, testTop}, coverage[1].Tests)
+	assert.Equal(t, []Function{testBottom, testTop}, coverage[2].Tests)
+	assert.Nil(t, coverage[3].Tests)
+}
@@ -0,0 +1,288 @@
+package cover
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"math"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	// ignoreDirective excludes the function it documents, or the statement
+	// that follows it or that it trails, from the report and the total.
+	ignoreDirective = "//deepcover:ignore"
+	// rootDirective marks the function it documents as an entry point, analysed
+	// like a matched test.
+	rootDirective = "//deepcover:root"
+)
+
+type lineRange struct {
+	start int
+	end   int
+}
+
+// ignoredLines are the line ranges of each file excluded by ignore directives.
+type ignoredLines map[string][]lineRange
+
+// contains reports whether the lines from start to end of a file fall within
+// an ignored range.
+func (i ignoredLines) contains(fileName string, start, end int) bool {
+	for _, r := range i[fileName] {
+		if r.start <= start && end <= r.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withFiles returns the ignored lines with every line of the files added.
+func (i ignoredLines) withFiles(fileNames []string) ignoredLines {
+	with := make(ignoredLines, len(i)+len(fileNames))
+	for fileName, ranges := range i {
+		with[fileName] = ranges
+	}
+	for _, fileName := range fileNames {
+		with[fileName] = append(slices.Clip(with[fileName]), lineRange{start: 1, end: math.MaxInt})
+	}
+
+	return with
+}
+
+// ignoresFunction reports whether fn is declared within an ignored range.
+func (i ignoredLines) ignoresFunction(fn *ssa.Function) bool {
+	if fn == nil || fn.Prog == nil || !fn.Pos().IsValid() {
+		return false
+	}
+
+	position := fn.Prog.Fset.Position(fn.Pos())
+	return i.contains(position.Filename, position.Line, position.Line)
+}
+
+type directives struct {
+	ignored ignoredLines
+	roots   []*types.Func
+	// generated are the files with a "Code generated ... DO NOT EDIT." header.
+	generated []string
+}
+
+// readDirectives reads the ignore directives and generated files of the
+// packages in the same module as pkgs, and the root directives of pkgs
+// themselves.
+func readDirectives(pkgs []*packages.Package) directives {
+	read := directives{ignored: ignoredLines{}}
+
+	modulePath := ""
+	for _, pkg := range pkgs {
+		if pkg.Module != nil {
+			modulePath = pkg.Module.Path
+			break
+		}
+	}
+
+	seen := map[string]bool{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module == nil || pkg.Module.Path != modulePath {
+			return
+		}
+
+		for _, file := range pkg.Syntax {
+			fileName := pkg.Fset.Position(file.Pos()).Filename
+			if seen[fileName] {
+				continue
+			}
+			seen[fileName] = true
+
+			if ast.IsGenerated(file) {
+				read.generated = append(read.generated, fileName)
+			}
+
+			ignored, _ := fileDirectives(pkg.Fset, file)
+			read.ignored[fileName] = ignored
+		}
+	})
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			_, roots := fileDirectives(pkg.Fset, file)
+			for _, decl := range roots {
+				if fn, ok := pkg.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+					read.roots = append(read.roots, fn)
+				}
+			}
+		}
+	}
+
+	return read
+}
+
+// fileDirectives returns the line ranges of a file that are ignored, and the
+// functions it marks as roots.
+func fileDirectives(fset *token.FileSet, file *ast.File) ([]lineRange, []*ast.FuncDecl) {
+	line := func(pos token.Pos) int {
+		return fset.Position(pos).Line
+	}
+
+	directiveLines := map[int]string{}
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if directive, ok := parseDirective(comment.Text); ok {
+				directiveLines[line(comment.Pos())] = directive
+			}
+		}
+	}
+	if len(directiveLines) == 0 {
+		return nil, nil
+	}
+
+	ignored := []lineRange{}
+	roots := []*ast.FuncDecl{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		if fn.Doc != nil {
+			for _, comment := range fn.Doc.List {
+				switch directive, _ := parseDirective(comment.Text); directive {
+				case ignoreDirective:
+					ignored = append(ignored, lineRange{start: line(fn.Pos()), end: line(fn.End())})
+				case rootDirective:
+					roots = append(roots, fn)
+				}
+			}
+		}
+
+		if fn.Body != nil {
+			ignored = append(ignored, ignoredStatements(fn.Body, directiveLines, line)...)
+		}
+	}
+
+	return ignored, roots
+}
+
+// ignoredStatements returns the line ranges of the statements in body that are
+// trailed by an ignore directive, or that follow one on a line of its own.
+func ignoredStatements(body *ast.BlockStmt, directiveLines map[int]string, line func(token.Pos) int) []lineRange {
+	starts := map[int]ast.Stmt{}
+	occupied := map[int]bool{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		stmt, ok := node.(ast.Stmt)
+		if !ok || node == body {
+			return true
+		}
+
+		start := line(stmt.Pos())
+		if _, ok := starts[start]; !ok {
+			starts[start] = stmt
+		}
+		occupied[start] = true
+		occupied[line(stmt.End())] = true
+		return true
+	})
+
+	ignored := []lineRange{}
+	for directiveLine, directive := range directiveLines {
+		if directive != ignoreDirective || directiveLine < line(body.Lbrace) || directiveLine > line(body.Rbrace) {
+			continue
+		}
+
+		stmt, ok := starts[directiveLine]
+		if !ok && !occupied[directiveLine] {
+			stmt, ok = starts[directiveLine+1]
+		}
+		if ok {
+			ignored = append(ignored, lineRange{start: line(stmt.Pos()), end: line(stmt.End())})
+		}
+	}
+
+	return ignored
+}
+
+// parseDirective returns the directive a comment holds, ignoring any reason
+// given after it.
+func parseDirective(text string) (string, bool) {
+	directive, _, _ := strings.Cut(text, " ")
+	switch directive {
+	case ignoreDirective, rootDirective:
+		return directive, true
+	}
+
+	return "", false
+}
+
+// findRootSSAFunctions returns the functions marked as roots, keyed like the
+// target functions.
+func findRootSSAFunctions(prog *ssa.Program, roots []*types.Func) (map[functionID]*ssa.Function, error) {
+	rootFuncs := make(map[functionID]*ssa.Function, len(roots))
+	for _, root := range roots {
+		fn := funcValue(prog, root)
+		if fn == nil {
+			continue
+		}
+		if fn.TypeParams().Len() > 0 {
+			return nil, fmt.Errorf("generic function %s cannot be a root", root.FullName())
+		}
+
+		rootFuncs[functionID{pkgPath: root.Pkg().Path(), receiver: functionReceiver(fn), funcName: fn.Name()}] = fn
+	}
+
+	return rootFuncs, nil
+}
+
+// ignoreBlocks drops the coverage blocks within ignored lines and recalculates
+// the coverage and statements of the functions they belonged to from the
+// remaining blocks, so that ignored code does not count towards the total.
+// Functions whose blocks are all ignored are removed.
+func ignoreBlocks(coverage []Coverage, ignored ignoredLines) []Coverage {
+	kept := make([]Coverage, 0, len(coverage))
+	for _, c := range coverage {
+		if len(ignored[c.File]) == 0 || len(c.Blocks) == 0 {
+			kept = append(kept, c)
+			continue
+		}
+
+		blocks := make([]Block, 0, len(c.Blocks))
+		for _, b := range c.Blocks {
+			if !ignored.contains(c.File, b.StartLine, b.EndLine) {
+				blocks = append(blocks, b)
+			}
+		}
+
+		switch {
+		case len(blocks) == 0:
+			continue
+		case len(blocks) < len(c.Blocks):
+			c.Statements = keptStatements(c.Statements, c.Blocks, blocks)
+			c.Blocks = blocks
+			c.Coverage = blockCoverage(blocks)
+		}
+		kept = append(kept, c)
+	}
+
+	return kept
+}
+
+// keptStatements scales the statements counted for a function by the share of
+// its coverprofile statements that are in the kept blocks.
+func keptStatements(statements int, blocks, kept []Block) int {
+	var total, remaining int
+	for _, b := range blocks {
+		total += b.NumStmt
+	}
+	for _, b := range kept {
+		remaining += b.NumStmt
+	}
+	if total == 0 {
+		return statements
+	}
+
+	return max(1, int(math.Round(float64(statements*remaining)/float64(total))))
+}
@@ -0,0 +1,224 @@
+package cover
+
+import (
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileDirectives(t *testing.T) {
+	tests := []struct {
+		name            string
+		src             string
+		expectedIgnored []lineRange
+		expectedRoots   []string
+	}{
+		{
+			name: "no directives",
+			src: `package p
+
+func A() {}
+`,
+		},
+		{
+			name: "ignored and root functions",
+			src: `package p
+
+// A does nothing.
+//deepcover:ignore generated
+func A() {
+}
+
+//deepcover:root
+func B() {}
+
+// C is not a root.
+// deepcover:root
+func C() {}
+`,
+			expectedIgnored: []lineRange{{start: 5, end: 6}},
+			expectedRoots:   []string{"B"},
+		},
+		{
+			name: "ignored statements",
+			src: `package p
+
+func A(err error) {
+	//deepcover:ignore
+	if err != nil {
+		panic(err)
+	}
+
+	switch {
+	case err == nil: //deepcover:ignore
+		return
+	}
+
+	println() //deepcover:ignore
+	println()
+}
+`,
+			expectedIgnored: []lineRange{{start: 5, end: 7}, {start: 10, end: 11}, {start: 14, end: 14}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "p.go", tt.src, parser.ParseComments)
+			require.NoError(t, err)
+
+			ignored, roots := fileDirectives(fset, file)
+			assert.ElementsMatch(t, tt.expectedIgnored, ignored)
+
+			names := []string{}
+			for _, root := range roots {
+				names = append(names, root.Name.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedRoots, names)
+		})
+	}
+}
+
+func TestFindRootSSAFunctions(t *testing.T) {
+	fn := buildSSAFunction(t, `func testFunc() {}
+
+	type Server struct{}
+
+	func (*Server) Serve() {}
+
+	type Client struct{}
+
+	func (Client) Serve() {}
+
+	func Map[T any](v T) T { return v }`)
+
+	pkg := fn.Pkg.Pkg
+	testFunc := pkg.Scope().Lookup("testFunc").(*types.Func)
+	serve := pkg.Scope().Lookup("Server").Type().(*types.Named).Method(0)
+	clientServe := pkg.Scope().Lookup("Client").Type().(*types.Named).Method(0)
+
+	roots, err := findRootSSAFunctions(fn.Prog, []*types.Func{testFunc, serve, clientServe})
+	require.NoError(t, err)
+	require.Len(t, roots, 3)
+	assert.Equal(t, fn, roots[functionID{pkgPath: pkg.Path(), funcName: "testFunc"}])
+	assert.Equal(t, "Serve", roots[functionID{pkgPath: pkg.Path(), receiver: "*Server", funcName: "Serve"}].Name())
+	assert.Equal(t, "Serve", roots[functionID{pkgPath: pkg.Path(), receiver: "Client", funcName: "Serve"}].Name())
+
+	_, err = findRootSSAFunctions(fn.Prog, []*types.Func{pkg.Scope().Lookup("Map").(*types.Func)})
+	assert.ErrorContains(t, err, "cannot be a root")
+}
+
+func TestIgnoredLinesWithFiles(t *testing.T) {
+	ignored := ignoredLines{"/mod/p.go": {{start: 5, end: 7}}}
+	with := ignored.withFiles([]string{"/mod/p.pb.go"})
+
+	assert.True(t, with.contains("/mod/p.go", 6, 6))
+	assert.False(t, with.contains("/mod/p.go", 8, 8))
+	assert.True(t, with.contains("/mod/p.pb.go", 1, 12000))
+	assert.False(t, ignored.contains("/mod/p.pb.go", 1, 1))
+}
+
+func TestIgnoreBlocks(t *testing.T) {
+	ignored := ignoredLines{"/mod/p.go": {{start: 5, end: 7}}}
+
+	tests := []struct {
+		name           string
+		coverage       []Coverage
+		expectedResult []Coverage
+	}{
+		{
+			name: "other file",
+			coverage: []Coverage{
+				{File: "/mod/q.go", Coverage: 50, Blocks: []Block{{StartLine: 5, EndLine: 6, NumStmt: 1}}},
+			},
+			expectedResult: []Coverage{
+				{File: "/mod/q.go", Coverage: 50, Blocks: []Block{{StartLine: 5, EndLine: 6, NumStmt: 1}}},
+			},
+		},
+		{
+			name: "ignored block",
+			coverage: []Coverage{
+				{
+					File:       "/mod/p.go",
+					Statements: 4,
+					Coverage:   75,
+					Blocks: []Block{
+						{StartLine: 3, EndLine: 5, NumStmt: 3, Count: 1},
+						{StartLine: 5, EndLine: 7, NumStmt: 1, Count: 0},
+					},
+				},
+			},
+			expectedResult: []Coverage{
+				{
+					File:       "/mod/p.go",
+					Statements: 3,
+					Coverage:   100,
+					Blocks:     []Block{{StartLine: 3, EndLine: 5, NumStmt: 3, Count: 1}},
+				},
+			},
+		},
+		{
+			name: "every block ignored",
+			coverage: []Coverage{
+				{File: "/mod/p.go", Coverage: 0, Blocks: []Block{{StartLine: 6, EndLine: 6, NumStmt: 1}}},
+			},
+			expectedResult: []Coverage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, ignoreBlocks(tt.coverage, ignored))
+		})
+	}
+}
+
+func TestIgnoreBlocksTotal(t *testing.T) {
+	ignored := ignoredLines{"/mod/p.go": {{start: 10, end: 19}}}
+	coverage := []Coverage{
+		{File: "/mod/q.go", Statements: 2, Coverage: 100, Blocks: []Block{{StartLine: 1, EndLine: 2, NumStmt: 2, Count: 1}}},
+		{
+			File:       "/mod/p.go",
+			Statements: 10,
+			Coverage:   20,
+			Blocks: []Block{
+				{StartLine: 1, EndLine: 5, NumStmt: 2, Count: 1},
+				{StartLine: 10, EndLine: 19, NumStmt: 8, Count: 0},
+			},
+		},
+	}
+	assert.InDelta(t, 33.33, calculateTotalCoverage(coverage), 0.01)
+
+	kept := ignoreBlocks(coverage, ignored)
+	require.Len(t, kept, 2)
+	assert.Equal(t, 2, kept[1].Statements)
+	assert.Equal(t, 100.0, calculateTotalCoverage(kept))
+}
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		text           string
+		expectedResult string
+		expectedOk     bool
+	}{
+		{text: "//deepcover:ignore", expectedResult: ignoreDirective, expectedOk: true},
+		{text: "//deepcover:ignore not reachable in tests", expectedResult: ignoreDirective, expectedOk: true},
+		{text: "//deepcover:root", expectedResult: rootDirective, expectedOk: true},
+		{text: "// deepcover:ignore"},
+		{text: "//deepcover:ignored"},
+		{text: "/*deepcover:ignore*/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			directive, ok := parseDirective(tt.text)
+			assert.Equal(t, tt.expectedResult, directive)
+			assert.Equal(t, tt.expectedOk, ok)
+		})
+	}
+}
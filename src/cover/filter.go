@@ -0,0 +1,158 @@
+package cover
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+)
+
+// globPrefix marks a filter pattern as a glob rather than a regular
+// expression.
+const globPrefix = "glob:"
+
+// filter selects the dependencies that are reported. Patterns are matched
+// against a function's qualified name, like "example.com/mod/store.Get" or
+// "example.com/mod/store.(*Store).Get" for a method, as unanchored regular
+// expressions or, with the glob: prefix, as globs. A glob is matched path
+// segment by segment against the end of the name, so that it matches packages
+// at any depth, and a "**" segment matches any number of segments.
+type filter struct {
+	include []pattern
+	exclude []pattern
+	ignored ignoredLines
+}
+
+// pattern is a compiled filter pattern, either a regular expression or the
+// path segments of a glob.
+type pattern struct {
+	regex *regexp.Regexp
+	glob  []string
+}
+
+func (p pattern) matches(name string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(name)
+	}
+
+	segments := strings.Split(name, "/")
+	for start := range segments {
+		if matchSegments(p.glob, segments[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments reports whether the glob segments match all of the name
+// segments, each with path.Match, and "**" with any number of them.
+func matchSegments(glob, segments []string) bool {
+	if len(glob) == 0 {
+		return len(segments) == 0
+	}
+
+	if glob[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(glob[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(glob[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(glob[1:], segments[1:])
+}
+
+// filterName returns the qualified name of a function that patterns are
+// matched against.
+func filterName(id functionID) string {
+	switch {
+	case id.receiver == "":
+		return id.pkgPath + "." + id.funcName
+	case strings.HasPrefix(id.receiver, "*"):
+		return id.pkgPath + ".(" + id.receiver + ")." + id.funcName
+	default:
+		return id.pkgPath + "." + id.receiver + "." + id.funcName
+	}
+}
+
+func newFilter(include, exclude []string) (filter, error) {
+	compile := func(patterns []string) ([]pattern, error) {
+		compiled := make([]pattern, len(patterns))
+		for i, expr := range patterns {
+			if glob, ok := strings.CutPrefix(expr, globPrefix); ok {
+				segments := strings.Split(glob, "/")
+				for _, segment := range segments {
+					if _, err := path.Match(segment, ""); err != nil {
+						return nil, fmt.Errorf("invalid pattern %q: %v", expr, err)
+					}
+				}
+				compiled[i] = pattern{glob: segments}
+				continue
+			}
+
+			regex, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", expr, err)
+			}
+			compiled[i] = pattern{regex: regex}
+		}
+		return compiled, nil
+	}
+
+	var f filter
+	var err error
+	if f.include, err = compile(include); err != nil {
+		return filter{}, err
+	}
+	if f.exclude, err = compile(exclude); err != nil {
+		return filter{}, err
+	}
+
+	return f, nil
+}
+
+// allows reports whether the function matches an include pattern, if there are
+// any, and no exclude pattern.
+func (f filter) allows(id functionID) bool {
+	name := filterName(id)
+
+	if len(f.include) > 0 {
+		included := false
+		for _, p := range f.include {
+			if p.matches(name) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, p := range f.exclude {
+		if p.matches(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reports reports whether the node's function is declared in source, allowed
+// and not declared within ignored lines.
+func (f filter) reports(node *callgraph.Node) bool {
+	if isSynthetic(node.Func) || declaredFunction(node.Func).Pkg == nil {
+		return false
+	}
+
+	return f.allows(nodeID(node)) && !f.ignored.ignoresFunction(node.Func)
+}
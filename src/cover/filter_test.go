@@ -0,0 +1,149 @@
+package cover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		include        []string
+		exclude        []string
+		id             functionID
+		expectedResult bool
+	}{
+		{
+			name:           "no patterns",
+			id:             functionID{pkgPath: "example.com/mod/pkg", funcName: "Top"},
+			expectedResult: true,
+		},
+		{
+			name:           "excluded package",
+			exclude:        []string{"/internal/testutil\\."},
+			id:             functionID{pkgPath: "example.com/mod/internal/testutil", funcName: "Helper"},
+			expectedResult: false,
+		},
+		{
+			name:           "excluded function",
+			exclude:        []string{"\\.Mock[A-Z]"},
+			id:             functionID{pkgPath: "example.com/mod/pkg", funcName: "MockStore"},
+			expectedResult: false,
+		},
+		{
+			name:           "not excluded",
+			exclude:        []string{"/mocks\\.", "\\.pb\\."},
+			id:             functionID{pkgPath: "example.com/mod/pkg", funcName: "Top"},
+			expectedResult: true,
+		},
+		{
+			name:           "included",
+			include:        []string{"/api\\.", "/store\\."},
+			id:             functionID{pkgPath: "example.com/mod/store", funcName: "Get"},
+			expectedResult: true,
+		},
+		{
+			name:           "not included",
+			include:        []string{"/api\\."},
+			id:             functionID{pkgPath: "example.com/mod/store", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "excluded by glob",
+			exclude:        []string{"glob:example.com/mod/*/mocks.*"},
+			id:             functionID{pkgPath: "example.com/mod/store/mocks", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "glob matches packages at any depth",
+			exclude:        []string{"glob:mocks.*"},
+			id:             functionID{pkgPath: "example.com/mod/store/mocks", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "glob with a slash matches packages at any depth",
+			exclude:        []string{"glob:*/mocks.*"},
+			id:             functionID{pkgPath: "example.com/mod/store/mocks", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "glob matches function names in any package",
+			exclude:        []string{"glob:*.compute"},
+			id:             functionID{pkgPath: "example.com/mod/pkg", funcName: "compute"},
+			expectedResult: false,
+		},
+		{
+			name:           "glob double star matches any number of segments",
+			exclude:        []string{"glob:example.com/**/testutil.*"},
+			id:             functionID{pkgPath: "example.com/mod/internal/testutil", funcName: "Helper"},
+			expectedResult: false,
+		},
+		{
+			name:           "glob segments match whole segments",
+			exclude:        []string{"glob:ocks.*"},
+			id:             functionID{pkgPath: "example.com/mod/store/mocks", funcName: "Get"},
+			expectedResult: true,
+		},
+		{
+			name:           "glob matches the receiver",
+			exclude:        []string{"glob:mocks.(\\*Mock).*"},
+			id:             functionID{pkgPath: "example.com/mod/mocks", receiver: "*Mock", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "glob does not match a function without the receiver",
+			exclude:        []string{"glob:mocks.(\\*Mock).*"},
+			id:             functionID{pkgPath: "example.com/mod/mocks", funcName: "Get"},
+			expectedResult: true,
+		},
+		{
+			name:           "regular expression matches the receiver",
+			exclude:        []string{`\.\(\*Mock\)\.Get$`},
+			id:             functionID{pkgPath: "example.com/mod/mocks", receiver: "*Mock", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "regular expression matches a value receiver",
+			include:        []string{`store\.Store\.Get$`},
+			id:             functionID{pkgPath: "example.com/mod/store", receiver: "Store", funcName: "Get"},
+			expectedResult: true,
+		},
+		{
+			name:           "glob star does not match slashes",
+			include:        []string{"glob:example.com/mod/*.Get"},
+			id:             functionID{pkgPath: "example.com/mod/store/mocks", funcName: "Get"},
+			expectedResult: false,
+		},
+		{
+			name:           "included by glob",
+			include:        []string{"glob:example.com/mod/store.[GS]et"},
+			id:             functionID{pkgPath: "example.com/mod/store", funcName: "Get"},
+			expectedResult: true,
+		},
+		{
+			name:           "exclude takes precedence",
+			include:        []string{"/store\\."},
+			exclude:        []string{"\\.Get$"},
+			id:             functionID{pkgPath: "example.com/mod/store", funcName: "Get"},
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newFilter(tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, f.allows(tt.id))
+		})
+	}
+}
+
+func TestNewFilterInvalidPattern(t *testing.T) {
+	_, err := newFilter(nil, []string{"("})
+	assert.ErrorContains(t, err, `invalid pattern "("`)
+
+	_, err = newFilter([]string{"glob:[a"}, nil)
+	assert.ErrorContains(t, err, `invalid pattern "glob:[a"`)
+}
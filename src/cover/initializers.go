@@ -0,0 +1,77 @@
+package cover
+
+// getInitDependencies returns the dependencies reached by the package
+// initializer of each target's package. Tests run it implicitly before any
+// test function, executing the init functions and package-level variable
+// initializers of the package and of every package it imports.
+func getInitDependencies(cgs analysis) (map[functionID][]dependency, error) {
+	return dependenciesFrom(cgs, cgs.initNodes)
+}
+
+func mergeDependencies(a, b map[functionID][]dependency) map[functionID][]dependency {
+	merged := make(map[functionID][]dependency, len(a))
+	for targetID, deps := range a {
+		merged[targetID] = append(merged[targetID], deps...)
+	}
+	for targetID, deps := range b {
+		merged[targetID] = append(merged[targetID], deps...)
+	}
+
+	return merged
+}
+
+// attributeInitializers adds each target to the tests of the functions its
+// package initialization reaches.
+func attributeInitializers(coverage []Coverage, initDependencies map[functionID][]dependency) {
+	reachedBy := map[functionID]map[Function]bool{}
+	for targetID, deps := range initDependencies {
+		target := Function{Package: targetID.pkgPath, Receiver: targetID.receiver, Name: targetID.funcName}
+		for _, dep := range deps {
+			if reachedBy[dep.functionID] == nil {
+				reachedBy[dep.functionID] = map[Function]bool{}
+			}
+			reachedBy[dep.functionID][target] = true
+		}
+	}
+
+	for i := range coverage {
+		targets := reachedBy[coverageID(coverage[i])]
+		if len(targets) == 0 {
+			continue
+		}
+
+		for _, test := range coverage[i].Tests {
+			targets[test] = true
+		}
+
+		tests := make([]Function, 0, len(targets))
+		for test := range targets {
+			tests = append(tests, test)
+		}
+		sortFunctions(tests)
+		coverage[i].Tests = tests
+	}
+}
+
+// separateInitializers splits the functions in coverage into those reached
+// from the targets and those only reached through package initialization.
+func separateInitializers(coverage []Coverage, dependenciesByTarget map[functionID][]dependency) ([]Coverage, []Coverage) {
+	reached := map[functionID]bool{}
+	for _, deps := range dependenciesByTarget {
+		for _, dep := range deps {
+			reached[dep.functionID] = true
+		}
+	}
+
+	functions := []Coverage{}
+	initializers := []Coverage{}
+	for _, c := range coverage {
+		if reached[coverageID(c)] {
+			functions = append(functions, c)
+		} else {
+			initializers = append(initializers, c)
+		}
+	}
+
+	return functions, initializers
+}
@@ -0,0 +1,60 @@
+package cover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDependencies(t *testing.T) {
+	targetID := functionID{pkgPath: "pkg", funcName: "TestA"}
+	otherID := functionID{pkgPath: "pkg", funcName: "TestB"}
+	top := dependency{functionID: functionID{pkgPath: "pkg", funcName: "Top"}}
+	init := dependency{functionID: functionID{pkgPath: "pkg", funcName: "init"}}
+
+	a := map[functionID][]dependency{targetID: {top}}
+	merged := mergeDependencies(a, map[functionID][]dependency{targetID: {init}, otherID: {init}})
+
+	assert.Equal(t, map[functionID][]dependency{
+		targetID: {top, init},
+		otherID:  {init},
+	}, merged)
+	assert.Equal(t, map[functionID][]dependency{targetID: {top}}, a)
+}
+
+func TestAttributeInitializers(t *testing.T) {
+	testA := Function{Package: "pkg", Name: "TestA"}
+	testB := Function{Package: "pkg", Name: "TestB"}
+	coverage := []Coverage{
+		{Package: "pkg", Name: "Top", Tests: []Function{testA}},
+		{Package: "pkg", Name: "init", Tests: nil},
+		{Package: "pkg", Name: "register", Tests: []Function{testB}},
+	}
+	register := dependency{functionID: functionID{pkgPath: "pkg", funcName: "register"}}
+	init := dependency{functionID: functionID{pkgPath: "pkg", funcName: "init"}}
+
+	attributeInitializers(coverage, map[functionID][]dependency{
+		{pkgPath: "pkg", funcName: "TestA"}: {init, register},
+		{pkgPath: "pkg", funcName: "TestB"}: {init, register},
+	})
+
+	assert.Equal(t, []Function{testA}, coverage[0].Tests)
+	assert.Equal(t, []Function{testA, testB}, coverage[1].Tests)
+	assert.Equal(t, []Function{testA, testB}, coverage[2].Tests)
+}
+
+func TestSeparateInitializers(t *testing.T) {
+	top := Coverage{Package: "pkg", Name: "Top"}
+	helper := Coverage{Package: "pkg", Name: "helper"}
+	init := Coverage{Package: "pkg", Name: "init"}
+
+	functions, initializers := separateInitializers([]Coverage{top, helper, init}, map[functionID][]dependency{
+		{pkgPath: "pkg", funcName: "TestA"}: {
+			{functionID: functionID{pkgPath: "pkg", funcName: "Top"}},
+			{functionID: functionID{pkgPath: "pkg", funcName: "helper"}},
+		},
+	})
+
+	assert.Equal(t, []Coverage{top, helper}, functions)
+	assert.Equal(t, []Coverage{init}, initializers)
+}
@@ -0,0 +1,61 @@
+package cover
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// getSetupDependencies returns the dependencies reached by the TestMain of
+// each target's package. go test calls TestMain in place of running the tests
+// directly, so its setup and teardown run around every test.
+func getSetupDependencies(cgs analysis) (map[functionID][]dependency, error) {
+	return dependenciesFrom(cgs, cgs.setupNodes)
+}
+
+// attributeSetup marks the functions in coverage that a TestMain reaches as
+// shared setup.
+func attributeSetup(coverage []Coverage, setupDependencies map[functionID][]dependency) {
+	setup := map[functionID]bool{}
+	for _, deps := range setupDependencies {
+		for _, dep := range deps {
+			setup[dep.functionID] = true
+		}
+	}
+
+	for i := range coverage {
+		coverage[i].Setup = setup[coverageID(coverage[i])]
+	}
+}
+
+// attributeTestMains sets the TestMain that runs around each of tests.
+func attributeTestMains(tests []Test, setupNodes map[functionID]*callgraph.Node) {
+	for i := range tests {
+		targetID := functionID{pkgPath: tests[i].Package, receiver: tests[i].Receiver, funcName: tests[i].Name}
+		if node, ok := setupNodes[targetID]; ok {
+			testMain := nodeFunction(node)
+			tests[i].TestMain = &testMain
+		}
+	}
+}
+
+const testMainName = "TestMain"
+
+// findTestMains returns the TestMain functions of pkgs, keyed by the package
+// under test. A TestMain declared in an external test package also runs the
+// tests of the package it tests.
+func findTestMains(pkgs []*ssa.Package) map[string]*ssa.Function {
+	testMains := map[string]*ssa.Function{}
+	for _, ssaPkg := range pkgs {
+		if fn := ssaPkg.Func(testMainName); fn != nil {
+			testMains[packageUnderTest(ssaPkg.Pkg.Path())] = fn
+		}
+	}
+
+	return testMains
+}
+
+func packageUnderTest(pkgPath string) string {
+	return strings.TrimSuffix(pkgPath, "_test")
+}
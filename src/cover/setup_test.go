@@ -0,0 +1,64 @@
+package cover
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestFindTestMains(t *testing.T) {
+	fn := buildSSAFunction(t, `func TestMain() {}
+
+	func testFunc() {}`)
+
+	pkgPath := fn.Pkg.Pkg.Path()
+	testMains := findTestMains([]*ssa.Package{fn.Pkg})
+	assert.Equal(t, map[string]*ssa.Function{pkgPath: fn.Pkg.Func("TestMain")}, testMains)
+
+	targets := findTargetSSAFunctions([]*ssa.Package{fn.Pkg}, regexp.MustCompile("."))
+	assert.NotContains(t, targets, functionID{pkgPath: pkgPath, funcName: "TestMain"})
+	assert.Contains(t, targets, functionID{pkgPath: pkgPath, funcName: "testFunc"})
+}
+
+func TestPackageUnderTest(t *testing.T) {
+	assert.Equal(t, "example.com/pkg", packageUnderTest("example.com/pkg"))
+	assert.Equal(t, "example.com/pkg", packageUnderTest("example.com/pkg_test"))
+}
+
+func TestAttributeSetup(t *testing.T) {
+	coverage := []Coverage{
+		{Package: "pkg", Name: "Top"},
+		{Package: "pkg", Name: "startServer"},
+	}
+
+	attributeSetup(coverage, map[functionID][]dependency{
+		{pkgPath: "pkg", funcName: "TestA"}: {{functionID: functionID{pkgPath: "pkg", funcName: "startServer"}}},
+	})
+
+	assert.False(t, coverage[0].Setup)
+	assert.True(t, coverage[1].Setup)
+}
+
+func TestAttributeTestMains(t *testing.T) {
+	fn := buildSSAFunction(t, `func TestMain() {}
+
+	func testFunc() {}`)
+
+	pkgPath := fn.Pkg.Pkg.Path()
+	tests := []Test{
+		{Function: Function{Package: pkgPath, Name: "TestA"}},
+		{Function: Function{Package: "other", Name: "TestB"}},
+	}
+
+	attributeTestMains(tests, map[functionID]*callgraph.Node{
+		{pkgPath: pkgPath, funcName: "TestA"}: {Func: fn.Pkg.Func("TestMain")},
+	})
+
+	require.NotNil(t, tests[0].TestMain)
+	assert.Equal(t, Function{Package: pkgPath, Name: "TestMain"}, *tests[0].TestMain)
+	assert.Nil(t, tests[1].TestMain)
+}
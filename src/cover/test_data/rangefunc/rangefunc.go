@@ -0,0 +1,26 @@
+package rangefunc
+
+import "iter"
+
+func Numbers(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range n {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func Sum(n int) int {
+	total := 0
+	for i := range Numbers(n) {
+		if i > 100 {
+			break
+		}
+		total += double(i)
+	}
+	return total
+}
+
+func double(i int) int { return i * 2 }
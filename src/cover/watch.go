@@ -0,0 +1,486 @@
+package cover
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Snapshot holds the contents of the Go files in a module, so that the
+// functions changed between two snapshots can be found.
+type Snapshot struct {
+	moduleDir  string
+	modulePath string
+	files      map[string][]byte
+}
+
+// TakeSnapshot reads every Go file in the module rooted at moduleDir,
+// skipping vendor, testdata and hidden directories.
+func TakeSnapshot(moduleDir string) (Snapshot, error) {
+	goMod, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read go.mod: %v", err)
+	}
+
+	snapshot := Snapshot{
+		moduleDir:  moduleDir,
+		modulePath: modfile.ModulePath(goMod),
+		files:      map[string][]byte{},
+	}
+
+	err = filepath.WalkDir(moduleDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			name := entry.Name()
+			if filePath != moduleDir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(filePath, ".go") {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		snapshot.files[filePath] = content
+		return nil
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read module files: %v", err)
+	}
+
+	return snapshot, nil
+}
+
+// Equal reports whether both snapshots hold the same files and contents.
+func (s Snapshot) Equal(other Snapshot) bool {
+	if len(s.files) != len(other.files) {
+		return false
+	}
+
+	for fileName, content := range s.files {
+		otherContent, ok := other.files[fileName]
+		if !ok || !bytes.Equal(content, otherContent) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ChangedFunctions returns the functions that were added, removed or changed
+// between previous and next. A change to a file's other declarations is
+// returned as a Function with an empty Name, standing for every function in
+// the package.
+func ChangedFunctions(previous, next Snapshot) ([]Function, error) {
+	fileNames := map[string]bool{}
+	for fileName := range previous.files {
+		fileNames[fileName] = true
+	}
+	for fileName := range next.files {
+		fileNames[fileName] = true
+	}
+
+	changed := map[Function]bool{}
+	for fileName := range fileNames {
+		before, after := previous.files[fileName], next.files[fileName]
+		if bytes.Equal(before, after) {
+			continue
+		}
+
+		beforeDecls, beforePkg, err := parseDeclarations(fileName, before)
+		if err != nil {
+			return nil, err
+		}
+		afterDecls, afterPkg, err := parseDeclarations(fileName, after)
+		if err != nil {
+			return nil, err
+		}
+
+		pkgName := afterPkg
+		if pkgName == "" {
+			pkgName = beforePkg
+		}
+
+		pkgPath := next.packagePath(fileName, pkgName)
+		for key := range mergeKeys(beforeDecls, afterDecls) {
+			if beforeDecls[key] == afterDecls[key] {
+				continue
+			}
+
+			receiver, name, _ := strings.Cut(key, ".")
+			changed[Function{Package: pkgPath, Receiver: receiver, Name: name}] = true
+		}
+	}
+
+	functions := make([]Function, 0, len(changed))
+	for fn := range changed {
+		functions = append(functions, fn)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Package != functions[j].Package {
+			return functions[i].Package < functions[j].Package
+		}
+		if functions[i].Name != functions[j].Name {
+			return functions[i].Name < functions[j].Name
+		}
+		return functions[i].Receiver < functions[j].Receiver
+	})
+
+	return functions, nil
+}
+
+// parseDeclarations returns the source of each function declared in a file,
+// keyed by receiver and name, and of its other declarations under the "."
+// key. It also returns the file's package name.
+func parseDeclarations(fileName string, src []byte) (map[string]string, string, error) {
+	decls := map[string]string{}
+	if src == nil {
+		return decls, "", nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %v", fileName, err)
+	}
+
+	source := func(node ast.Node) string {
+		return string(src[fset.Position(node.Pos()).Offset:fset.Position(node.End()).Offset])
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			decls["."] += source(decl)
+			continue
+		}
+
+		receiver := ""
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			receiver = source(fn.Recv.List[0].Type)
+		}
+		decls[receiver+"."+fn.Name.Name] = source(fn)
+	}
+
+	return decls, file.Name.Name, nil
+}
+
+func mergeKeys(a, b map[string]string) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+
+	return keys
+}
+
+// packagePath returns the import path of the package a file belongs to, as it
+// appears in Function.Package.
+func (s Snapshot) packagePath(fileName, pkgName string) string {
+	pkgPath := s.modulePath
+	if rel, err := filepath.Rel(s.moduleDir, filepath.Dir(fileName)); err == nil && rel != "." {
+		pkgPath = path.Join(s.modulePath, filepath.ToSlash(rel))
+	}
+
+	if strings.HasSuffix(pkgName, "_test") {
+		pkgPath += "_test"
+	}
+
+	return pkgPath
+}
+
+// AffectedTests returns the tests in result that reach one of the changed
+// functions, together with changed functions that are new tests matching
+// target.
+func AffectedTests(result Result, changed []Function, target string) ([]Function, error) {
+	targetRegex, err := regexp.Compile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	isChanged := func(fn Function) bool {
+		for _, c := range changed {
+			if c.Package == fn.Package && (c.Name == "" || c.Name == fn.Name && sameReceiver(c.Receiver, fn.Receiver)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	affected := map[Function]bool{}
+	known := map[Function]bool{}
+	for _, test := range result.Tests {
+		known[test.Function] = true
+
+		reached := []Function{test.Function}
+		for _, call := range test.Calls {
+			reached = append(reached, call.Caller, call.Callee)
+		}
+		for _, fn := range reached {
+			if isChanged(fn) {
+				affected[test.Function] = true
+				break
+			}
+		}
+	}
+
+	for _, fn := range changed {
+		if !known[fn] && fn.Receiver == "" && strings.HasPrefix(fn.Name, "Test") && targetRegex.MatchString(fn.Name) {
+			affected[fn] = true
+		}
+	}
+
+	tests := make([]Function, 0, len(affected))
+	for test := range affected {
+		tests = append(tests, test)
+	}
+	sortFunctions(tests)
+
+	return tests, nil
+}
+
+// sameReceiver reports whether a receiver read from source, like "*List[K,V]",
+// and one formatted from its type, like "*List[K, V]", are the same.
+func sameReceiver(a, b string) bool {
+	return strings.ReplaceAll(a, " ", "") == strings.ReplaceAll(b, " ", "")
+}
+
+// AffectedPackages groups tests by the directory of the package declaring
+// them, so that only those packages are loaded and analysed again, each with
+// only its own tests.
+func AffectedPackages(snapshot Snapshot, tests []Function) map[string][]Function {
+	byDir := map[string][]Function{}
+	for _, test := range tests {
+		dir := snapshot.packageDir(test.Package)
+		byDir[dir] = append(byDir[dir], test)
+	}
+
+	return byDir
+}
+
+// packageDir returns the directory of the module package with the given
+// import path, which may be that of an external test package.
+func (s Snapshot) packageDir(pkgPath string) string {
+	rel := strings.TrimPrefix(packageUnderTest(pkgPath), s.modulePath)
+	return filepath.Join(s.moduleDir, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+}
+
+// TestsPattern returns a regular expression matching exactly the named tests.
+func TestsPattern(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+// Merge combines the result of re-running the tests in rerun into the previous
+// result. Functions that are also reached by tests that were not re-run keep
+// the blocks those tests covered.
+func Merge(previous, partial Result, rerun []Function) Result {
+	isRerun := make(map[Function]bool, len(rerun))
+	for _, test := range rerun {
+		isRerun[test] = true
+	}
+
+	tests := []Test{}
+	for _, test := range previous.Tests {
+		if !isRerun[test.Function] {
+			tests = append(tests, test)
+		}
+	}
+	tests = append(tests, partial.Tests...)
+	sort.Slice(tests, func(i, j int) bool {
+		if tests[i].Package != tests[j].Package {
+			return tests[i].Package < tests[j].Package
+		}
+		return tests[i].Name < tests[j].Name
+	})
+
+	partialCoverage := make(map[Function]Coverage, len(partial.Coverage))
+	for _, c := range partial.Coverage {
+		partialCoverage[c.Function()] = c
+	}
+
+	coverage := []Coverage{}
+	merged := map[Function]bool{}
+	for _, c := range previous.Coverage {
+		fn := c.Function()
+
+		others := []Function{}
+		for _, test := range c.Tests {
+			if !isRerun[test] {
+				others = append(others, test)
+			}
+		}
+
+		p, ok := partialCoverage[fn]
+		switch {
+		case ok && len(others) == 0:
+			coverage = append(coverage, p)
+		case ok:
+			coverage = append(coverage, mergeCoverage(c, p, others))
+		case len(others) > 0:
+			c.Tests = others
+			coverage = append(coverage, c)
+		}
+		merged[fn] = true
+	}
+
+	for _, c := range partial.Coverage {
+		if !merged[c.Function()] {
+			coverage = append(coverage, c)
+		}
+	}
+
+	moduleDir := partial.ModuleDir
+	if moduleDir == "" {
+		moduleDir = previous.ModuleDir
+	}
+
+	// Package initialization runs for every test, so the re-run tests reach
+	// the same initializers.
+	initializers := partial.Initializers
+	if initializers == nil {
+		initializers = previous.Initializers
+	}
+
+	return Result{
+		Coverage:            coverage,
+		ApproxTotalCoverage: calculateTotalCoverage(coverage),
+		Tests:               tests,
+		ModuleDir:           moduleDir,
+		TestHelpers:         mergeTestHelpers(previous.TestHelpers, partial.TestHelpers, isRerun),
+		Initializers:        initializers,
+	}
+}
+
+// mergeTestHelpers combines the test helpers reached by the re-run tests with
+// those still reached by the tests that were not re-run.
+func mergeTestHelpers(previous, partial []Coverage, isRerun map[Function]bool) []Coverage {
+	if previous == nil && partial == nil {
+		return nil
+	}
+
+	partialHelpers := make(map[Function]Coverage, len(partial))
+	for _, helper := range partial {
+		partialHelpers[helper.Function()] = helper
+	}
+
+	helpers := []Coverage{}
+	merged := map[Function]bool{}
+	for _, helper := range previous {
+		others := []Function{}
+		for _, test := range helper.Tests {
+			if !isRerun[test] {
+				others = append(others, test)
+			}
+		}
+
+		p, ok := partialHelpers[helper.Function()]
+		switch {
+		case ok:
+			p.Tests = append(others, p.Tests...)
+			sortFunctions(p.Tests)
+			p.Tests = slices.Compact(p.Tests)
+			helpers = append(helpers, p)
+		case len(others) > 0:
+			helper.Tests = others
+			helpers = append(helpers, helper)
+		}
+		merged[helper.Function()] = true
+	}
+
+	for _, helper := range partial {
+		if !merged[helper.Function()] {
+			helpers = append(helpers, helper)
+		}
+	}
+
+	return helpers
+}
+
+// mergeCoverage combines the coverage of a function from a partial re-run
+// with the blocks covered by the tests in others. The function is unchanged,
+// as otherwise every test reaching it is re-run, but it may have moved.
+func mergeCoverage(previous, partial Coverage, others []Function) Coverage {
+	if len(previous.Blocks) == 0 || len(partial.Blocks) == 0 {
+		if previous.Coverage > partial.Coverage {
+			partial.Coverage = previous.Coverage
+		}
+	} else {
+		offset := partial.StartLine - previous.StartLine
+		counts := map[Block]int{}
+		for _, b := range previous.Blocks {
+			b.StartLine += offset
+			b.EndLine += offset
+			count := b.Count
+			b.Count = 0
+			counts[b] = count
+		}
+
+		blocks := make([]Block, len(partial.Blocks))
+		for i, b := range partial.Blocks {
+			key := b
+			key.Count = 0
+			if counts[key] > b.Count {
+				b.Count = counts[key]
+			}
+			blocks[i] = b
+		}
+		partial.Blocks = blocks
+		partial.Coverage = blockCoverage(blocks)
+	}
+
+	tests := append(others, partial.Tests...)
+	sortFunctions(tests)
+	partial.Tests = tests[:0]
+	for i, test := range tests {
+		if i == 0 || test != tests[i-1] {
+			partial.Tests = append(partial.Tests, test)
+		}
+	}
+
+	return partial
+}
+
+// blockCoverage is the percentage of statements in blocks that were executed,
+// as reported by go tool cover.
+func blockCoverage(blocks []Block) float64 {
+	var total, covered int
+	for _, b := range blocks {
+		total += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
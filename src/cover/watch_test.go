@@ -0,0 +1,419 @@
+package cover
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const watchTestSource = `package pkg
+
+const limit = 10
+
+func Top() int {
+	return Bottom() + 1
+}
+
+func Bottom() int {
+	return limit
+}
+
+func (t *T) Method() {}
+`
+
+func writeWatchModule(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/mod\n\ngo 1.22\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	dir := writeWatchModule(t, map[string]string{
+		"pkg/pkg.go":              watchTestSource,
+		"pkg/README.md":           "not go",
+		"pkg/testdata/data.go":    "package data",
+		"vendor/dep/dep.go":       "package dep",
+		".hidden/hidden.go":       "package hidden",
+		"pkg/sub/sub_test.go":     "package sub_test",
+		"pkg/sub/nested/other.go": "package nested",
+	})
+
+	snapshot, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+
+	files := []string{}
+	for file := range snapshot.files {
+		rel, err := filepath.Rel(dir, file)
+		require.NoError(t, err)
+		files = append(files, filepath.ToSlash(rel))
+	}
+
+	assert.Equal(t, "example.com/mod", snapshot.modulePath)
+	assert.ElementsMatch(t, []string{"pkg/pkg.go", "pkg/sub/sub_test.go", "pkg/sub/nested/other.go"}, files)
+
+	unchanged, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+	assert.True(t, snapshot.Equal(unchanged))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg/pkg.go"), []byte(watchTestSource+"\n"), 0o644))
+	changed, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+	assert.False(t, snapshot.Equal(changed))
+
+	_, err = TakeSnapshot(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestChangedFunctions(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           string
+		before         string
+		after          string
+		expectedResult []Function
+		expectedErr    bool
+	}{
+		{
+			name:           "unchanged",
+			file:           "pkg/pkg.go",
+			before:         watchTestSource,
+			after:          watchTestSource,
+			expectedResult: []Function{},
+		},
+		{
+			name:           "function body changed",
+			file:           "pkg/pkg.go",
+			before:         watchTestSource,
+			after:          strings.Replace(watchTestSource, "Bottom() + 1", "Bottom() + 2", 1),
+			expectedResult: []Function{{Package: "example.com/mod/pkg", Name: "Top"}},
+		},
+		{
+			name:           "function moved without changes",
+			file:           "pkg/pkg.go",
+			before:         watchTestSource,
+			after:          strings.Replace(watchTestSource, "func Top", "\n\nfunc Top", 1),
+			expectedResult: []Function{},
+		},
+		{
+			name:           "method changed",
+			file:           "pkg/pkg.go",
+			before:         watchTestSource,
+			after:          strings.Replace(watchTestSource, "Method() {}", "Method() { _ = 1 }", 1),
+			expectedResult: []Function{{Package: "example.com/mod/pkg", Receiver: "*T", Name: "Method"}},
+		},
+		{
+			name:           "other declaration changed",
+			file:           "pkg/pkg.go",
+			before:         watchTestSource,
+			after:          strings.Replace(watchTestSource, "limit = 10", "limit = 20", 1),
+			expectedResult: []Function{{Package: "example.com/mod/pkg", Name: ""}},
+		},
+		{
+			name:  "file added to an external test package",
+			file:  "pkg/pkg_test.go",
+			after: "package pkg_test\n\nfunc TestTop(t *testing.T) {}\n",
+			expectedResult: []Function{
+				{Package: "example.com/mod/pkg_test", Name: "TestTop"},
+			},
+		},
+		{
+			name:   "file removed",
+			file:   "main.go",
+			before: "package main\n\nfunc main() {}\n",
+			expectedResult: []Function{
+				{Package: "example.com/mod", Name: "main"},
+			},
+		},
+		{
+			name:        "syntax error",
+			file:        "pkg/pkg.go",
+			before:      watchTestSource,
+			after:       "package pkg\n\nfunc Top( {\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeWatchModule(t, map[string]string{})
+			file := filepath.Join(dir, tt.file)
+
+			snapshot := func(content string) Snapshot {
+				s := Snapshot{moduleDir: dir, modulePath: "example.com/mod", files: map[string][]byte{}}
+				if content != "" {
+					s.files[file] = []byte(content)
+				}
+				return s
+			}
+
+			result, err := ChangedFunctions(snapshot(tt.before), snapshot(tt.after))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestAffectedTests(t *testing.T) {
+	result := Result{
+		Tests: []Test{
+			{
+				Function: Function{Package: "pkg", Name: "TestTop"},
+				Calls: []Call{
+					{Caller: Function{Package: "pkg", Name: "TestTop"}, Callee: Function{Package: "pkg", Name: "Top"}},
+					{Caller: Function{Package: "pkg", Name: "Top"}, Callee: Function{Package: "pkg/sub", Name: "Sub"}},
+				},
+			},
+			{
+				Function: Function{Package: "pkg", Name: "TestBottom"},
+				Calls: []Call{
+					{Caller: Function{Package: "pkg", Name: "TestBottom"}, Callee: Function{Package: "pkg", Name: "Bottom"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		changed        []Function
+		target         string
+		expectedResult []Function
+	}{
+		{
+			name:           "deep dependency changed",
+			changed:        []Function{{Package: "pkg/sub", Name: "Sub"}},
+			target:         "Test",
+			expectedResult: []Function{{Package: "pkg", Name: "TestTop"}},
+		},
+		{
+			name:           "test changed",
+			changed:        []Function{{Package: "pkg", Name: "TestBottom"}},
+			target:         "Test",
+			expectedResult: []Function{{Package: "pkg", Name: "TestBottom"}},
+		},
+		{
+			name:           "package declarations changed",
+			changed:        []Function{{Package: "pkg", Name: ""}},
+			target:         "Test",
+			expectedResult: []Function{{Package: "pkg", Name: "TestBottom"}, {Package: "pkg", Name: "TestTop"}},
+		},
+		{
+			name:           "method of another type changed",
+			changed:        []Function{{Package: "pkg", Receiver: "*T", Name: "Top"}},
+			target:         "Test",
+			expectedResult: []Function{},
+		},
+		{
+			name:           "unreached function changed",
+			changed:        []Function{{Package: "pkg/sub", Name: "Other"}},
+			target:         "Test",
+			expectedResult: []Function{},
+		},
+		{
+			name:           "new test matching target",
+			changed:        []Function{{Package: "pkg", Name: "TestNew"}, {Package: "pkg", Name: "TestOther"}},
+			target:         "New",
+			expectedResult: []Function{{Package: "pkg", Name: "TestNew"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := AffectedTests(result, tt.changed, tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestAffectedPackages(t *testing.T) {
+	snapshot := Snapshot{moduleDir: "/mod", modulePath: "example.com/mod"}
+	top := Function{Package: "example.com/mod/pkg", Name: "TestTop"}
+	external := Function{Package: "example.com/mod/pkg_test", Name: "TestExternal"}
+	root := Function{Package: "example.com/mod", Name: "TestRoot"}
+	sub := Function{Package: "example.com/mod/sub", Name: "TestTop"}
+
+	assert.Equal(t, map[string][]Function{
+		filepath.FromSlash("/mod/pkg"): {top, external},
+		filepath.FromSlash("/mod/sub"): {sub},
+		filepath.FromSlash("/mod"):     {root},
+	}, AffectedPackages(snapshot, []Function{top, external, sub, root}))
+}
+
+func TestTestsPattern(t *testing.T) {
+	assert.Equal(t, `^(TestA|Test\.B)$`, TestsPattern([]string{"TestA", "Test.B"}))
+}
+
+var (
+	testA = Function{Package: "pkg", Name: "TestA"}
+	testB = Function{Package: "pkg", Name: "TestB"}
+)
+
+func TestMerge(t *testing.T) {
+	previous := Result{
+		Coverage: []Coverage{
+			{
+				Package: "pkg", Name: "Top", Statements: 2, Coverage: 50, StartLine: 3,
+				Blocks: []Block{
+					{StartLine: 3, EndLine: 4, NumStmt: 1, Count: 1},
+					{StartLine: 5, EndLine: 5, NumStmt: 1, Count: 0},
+				},
+				Tests: []Function{testA, testB},
+			},
+			{Package: "pkg", Name: "OnlyA", Statements: 2, Coverage: 100, Tests: []Function{testA}},
+			{Package: "pkg", Name: "OnlyB", Statements: 2, Coverage: 0, Tests: []Function{testB}},
+		},
+		Tests: []Test{
+			{Function: testA},
+			{Function: testB},
+		},
+		ModuleDir: "/mod",
+	}
+
+	partial := Result{
+		Coverage: []Coverage{
+			{
+				Package: "pkg", Name: "Top", Statements: 2, Coverage: 50, StartLine: 5,
+				Blocks: []Block{
+					{StartLine: 5, EndLine: 6, NumStmt: 1, Count: 0},
+					{StartLine: 7, EndLine: 7, NumStmt: 1, Count: 2},
+				},
+				Tests: []Function{testB},
+			},
+			{Package: "pkg", Name: "NewB", Statements: 2, Coverage: 100, Tests: []Function{testB}},
+		},
+		Tests: []Test{
+			{Function: testB, Calls: []Call{{}}},
+		},
+	}
+
+	merged := Merge(previous, partial, []Function{testB})
+
+	require.Len(t, merged.Coverage, 3)
+
+	top := merged.Coverage[0]
+	assert.Equal(t, "Top", top.Name)
+	assert.Equal(t, 100.0, top.Coverage)
+	assert.Equal(t, []Function{testA, testB}, top.Tests)
+	assert.Equal(t, []Block{
+		{StartLine: 5, EndLine: 6, NumStmt: 1, Count: 1},
+		{StartLine: 7, EndLine: 7, NumStmt: 1, Count: 2},
+	}, top.Blocks)
+
+	assert.Equal(t, "OnlyA", merged.Coverage[1].Name)
+	assert.Equal(t, "NewB", merged.Coverage[2].Name)
+
+	require.Len(t, merged.Tests, 2)
+	assert.Equal(t, "TestA", merged.Tests[0].Name)
+	assert.Equal(t, []Call{{}}, merged.Tests[1].Calls)
+
+	assert.Equal(t, "/mod", merged.ModuleDir)
+	assert.Equal(t, 100.0, merged.ApproxTotalCoverage)
+}
+
+func TestMergeTestHelpers(t *testing.T) {
+	previous := Result{
+		TestHelpers: []Coverage{
+			{Package: "pkg", Name: "setup", Tests: []Function{testA, testB}},
+			{Package: "pkg", Name: "onlyA", Tests: []Function{testA}},
+			{Package: "pkg", Name: "onlyB", Tests: []Function{testB}},
+		},
+		Initializers: []Coverage{{Package: "pkg", Name: "init"}},
+	}
+	partial := Result{
+		TestHelpers: []Coverage{
+			{Package: "pkg", Name: "setup", Tests: []Function{testB}},
+			{Package: "pkg", Name: "newB", Tests: []Function{testB}},
+		},
+	}
+
+	merged := Merge(previous, partial, []Function{testB})
+
+	assert.Equal(t, []Coverage{
+		{Package: "pkg", Name: "setup", Tests: []Function{testA, testB}},
+		{Package: "pkg", Name: "onlyA", Tests: []Function{testA}},
+		{Package: "pkg", Name: "newB", Tests: []Function{testB}},
+	}, merged.TestHelpers)
+	assert.Equal(t, previous.Initializers, merged.Initializers)
+
+	assert.Nil(t, Merge(Result{}, Result{}, []Function{testB}).TestHelpers)
+}
+
+func TestMergeMethodsWithTheSameName(t *testing.T) {
+	previous := Result{
+		Coverage: []Coverage{
+			{Package: "pkg", Receiver: "A", Name: "String", Statements: 1, Coverage: 100, Tests: []Function{testA}},
+			{Package: "pkg", Receiver: "B", Name: "String", Statements: 1, Coverage: 0, Tests: []Function{testB}},
+		},
+	}
+	partial := Result{
+		Coverage: []Coverage{
+			{Package: "pkg", Receiver: "B", Name: "String", Statements: 1, Coverage: 100, Tests: []Function{testB}},
+		},
+	}
+
+	merged := Merge(previous, partial, []Function{testB})
+
+	assert.Equal(t, []Coverage{
+		{Package: "pkg", Receiver: "A", Name: "String", Statements: 1, Coverage: 100, Tests: []Function{testA}},
+		{Package: "pkg", Receiver: "B", Name: "String", Statements: 1, Coverage: 100, Tests: []Function{testB}},
+	}, merged.Coverage)
+}
+
+func TestWatchSameNamedTests(t *testing.T) {
+	testA := Function{Package: "mod/a", Name: "TestA"}
+	testB := Function{Package: "mod/b", Name: "TestA"}
+	shared := Function{Package: "mod/lib", Name: "Shared"}
+	setupA := Function{Package: "mod/a", Name: "Setup"}
+	setupB := Function{Package: "mod/b", Name: "Setup"}
+
+	previous := Result{
+		Coverage: []Coverage{
+			{Package: "mod/a", Name: "Setup", Statements: 1, Coverage: 100, Tests: []Function{testA}},
+			{Package: "mod/b", Name: "Setup", Statements: 1, Coverage: 0, Tests: []Function{testB}},
+			{Package: "mod/lib", Name: "Shared", Statements: 1, Coverage: 100, Tests: []Function{testA, testB}},
+		},
+		Tests: []Test{
+			{Function: testA, Calls: []Call{{Caller: testA, Callee: setupA}, {Caller: setupA, Callee: shared}}},
+			{Function: testB, Calls: []Call{{Caller: testB, Callee: setupB}, {Caller: setupB, Callee: shared}}},
+		},
+	}
+
+	rerun, err := AffectedTests(previous, []Function{setupB}, "Test")
+	require.NoError(t, err)
+	assert.Equal(t, []Function{testB}, rerun)
+
+	snapshot := Snapshot{moduleDir: "/mod", modulePath: "mod"}
+	assert.Equal(t, map[string][]Function{
+		filepath.FromSlash("/mod/b"): {testB},
+	}, AffectedPackages(snapshot, rerun))
+
+	partial := Result{
+		Coverage: []Coverage{
+			{Package: "mod/b", Name: "Setup", Statements: 1, Coverage: 100, Tests: []Function{testB}},
+			{Package: "mod/lib", Name: "Shared", Statements: 1, Coverage: 100, Tests: []Function{testB}},
+		},
+		Tests: []Test{previous.Tests[1]},
+	}
+
+	merged := Merge(previous, partial, rerun)
+
+	assert.Equal(t, []Coverage{
+		{Package: "mod/a", Name: "Setup", Statements: 1, Coverage: 100, Tests: []Function{testA}},
+		{Package: "mod/b", Name: "Setup", Statements: 1, Coverage: 100, Tests: []Function{testB}},
+		{Package: "mod/lib", Name: "Shared", Statements: 1, Coverage: 100, Tests: []Function{testA, testB}},
+	}, merged.Coverage)
+	assert.Equal(t, previous.Tests, merged.Tests)
+}
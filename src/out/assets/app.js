@@ -0,0 +1,128 @@
+"use strict";
+
+let result = null;
+
+async function fetchJSON(url, options) {
+  const response = await fetch(url, options);
+  if (!response.ok) {
+    throw new Error(await response.text());
+  }
+  return response.json();
+}
+
+function element(tag, attributes, ...children) {
+  const el = document.createElement(tag);
+  Object.assign(el, attributes);
+  el.append(...children);
+  return el;
+}
+
+function percentage(coverage) {
+  const level = coverage >= 80 ? "high" : coverage >= 50 ? "medium" : "low";
+  return element("span", { className: level }, coverage.toFixed(1) + "%");
+}
+
+function query(fn) {
+  return "package=" + encodeURIComponent(fn.Package) + "&receiver=" + encodeURIComponent(fn.Receiver || "") +
+    "&name=" + encodeURIComponent(fn.Name);
+}
+
+function functionName(fn) {
+  if (!fn.Receiver) {
+    return fn.Name;
+  }
+  return (fn.Receiver.startsWith("*") ? "(" + fn.Receiver + ")" : fn.Receiver) + "." + fn.Name;
+}
+
+function render() {
+  document.getElementById("total").textContent = "Total: " + result.ApproxTotalCoverage.toFixed(2) + "%";
+
+  const tests = document.getElementById("tests");
+  tests.replaceChildren(...(result.Tests || []).map((test) =>
+    element("li", { onclick: () => showTree(test.Function) }, test.Function.Name, " ",
+      element("span", { className: "package" }, test.Function.Package))));
+
+  renderFunctions();
+}
+
+function renderFunctions() {
+  const search = document.getElementById("search").value.toLowerCase();
+  const functions = (result.Coverage || []).filter((c) =>
+    (c.Package + "." + functionName(c) + " " + c.Path).toLowerCase().includes(search));
+
+  document.getElementById("functions").replaceChildren(...functions.map((c) =>
+    element("li", { onclick: () => showSource({ Package: c.Package, Receiver: c.Receiver, Name: c.Name }) },
+      functionName(c), " ", percentage(c.Coverage), " ", element("span", { className: "package" }, c.Package))));
+}
+
+function treeNode(node) {
+  const name = element("span", {}, node.Function.Package + "." + functionName(node.Function));
+  const li = element("li", {}, name);
+  if (node.Coverage) {
+    name.className = "name";
+    name.onclick = () => showSource(node.Function);
+    li.append(" ", percentage(node.Coverage.Coverage));
+  }
+  if (node.Setup) {
+    li.append(" ", element("span", { className: "marker" }, "(shared setup)"));
+  }
+  if (node.Cycle) {
+    li.append(" ", element("span", { className: "marker" }, "(cycle)"));
+  } else if (node.Shared) {
+    li.append(" ", element("span", { className: "marker" }, "(shown above)"));
+  } else if (node.Children) {
+    li.append(" ", element("span", { className: "marker" }, "subtree "), percentage(node.Cumulative));
+    li.append(element("ul", { className: "tree" }, ...node.Children.map(treeNode)));
+  }
+  return li;
+}
+
+async function showTree(fn) {
+  const view = document.getElementById("view");
+  try {
+    const tree = await fetchJSON("api/tree?" + query(fn));
+    view.replaceChildren(element("h2", {}, fn.Name), element("ul", { className: "tree" }, treeNode(tree)));
+  } catch (err) {
+    view.replaceChildren(element("p", { className: "marker" }, err.message));
+  }
+}
+
+async function showSource(fn) {
+  const view = document.getElementById("view");
+  const c = result.Coverage.find((c) =>
+    c.Package === fn.Package && (c.Receiver || "") === (fn.Receiver || "") && c.Name === fn.Name);
+  const heading = element("h2", {}, functionName(fn), " ", percentage(c.Coverage), " ", element("span", { className: "package" }, c.Path));
+  try {
+    const source = await fetchJSON("api/source?" + query(fn));
+    const pre = element("pre", {}, ...source.map((line) =>
+      element("div", { className: line.Class }, element("span", { className: "lineno" }, String(line.Number)), line.Text)));
+    const tests = element("p", { className: "marker" }, "Reached by: " + (c.Tests || []).map((test) => test.Name).join(", "));
+    view.replaceChildren(heading, tests, pre);
+  } catch (err) {
+    view.replaceChildren(heading, element("p", { className: "marker" }, err.message));
+  }
+}
+
+async function rerun() {
+  const button = document.getElementById("rerun");
+  const status = document.getElementById("status");
+  button.disabled = true;
+  status.textContent = "Running...";
+  try {
+    result = await fetchJSON("api/rerun", { method: "POST" });
+    render();
+    status.textContent = "Updated " + new Date().toLocaleTimeString();
+  } catch (err) {
+    status.textContent = err.message;
+  } finally {
+    button.disabled = false;
+  }
+}
+
+document.getElementById("search").addEventListener("input", renderFunctions);
+document.getElementById("rerun").addEventListener("click", rerun);
+
+fetchJSON("api/result").then((data) => {
+  result = data;
+  render();
+});
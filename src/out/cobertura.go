@@ -0,0 +1,171 @@
+package out
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+func OutputCobertura(path string, coverage cover.Result) error {
+	report, err := formatCobertura(coverage, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to format cobertura report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity float64           `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity float64         `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int  `xml:"number,attr"`
+	Hits   int  `xml:"hits,attr"`
+	Branch bool `xml:"branch,attr"`
+}
+
+func formatCobertura(coverage cover.Result, timestamp time.Time) (string, error) {
+	report := coberturaCoverage{
+		Timestamp: timestamp.UnixMilli(),
+	}
+	if coverage.ModuleDir != "" {
+		report.Sources = []string{coverage.ModuleDir}
+	}
+
+	type classKey struct {
+		pkg      string
+		filename string
+	}
+
+	packages := map[string]*coberturaPackage{}
+	classes := map[classKey]*coberturaClass{}
+	for _, funcCoverage := range coverage.Coverage {
+		if _, ok := packages[funcCoverage.Package]; !ok {
+			packages[funcCoverage.Package] = &coberturaPackage{Name: funcCoverage.Package}
+		}
+
+		filename := relativeFile(coverage.ModuleDir, funcCoverage)
+		key := classKey{pkg: funcCoverage.Package, filename: filename}
+		class, ok := classes[key]
+		if !ok {
+			class = &coberturaClass{Name: filename, Filename: filename}
+			classes[key] = class
+		}
+
+		method := coberturaMethod{Name: functionName(funcCoverage.Function())}
+		for _, line := range coverageLines(funcCoverage) {
+			method.Lines = append(method.Lines, coberturaLine{Number: line.Line, Hits: line.Count})
+		}
+		method.LineRate = lineRate(method.Lines)
+
+		class.Methods = append(class.Methods, method)
+		class.Lines = append(class.Lines, method.Lines...)
+	}
+
+	for key, class := range classes {
+		sort.Slice(class.Lines, func(i, j int) bool {
+			return class.Lines[i].Number < class.Lines[j].Number
+		})
+		class.LineRate = lineRate(class.Lines)
+
+		packages[key.pkg].Classes = append(packages[key.pkg].Classes, *class)
+	}
+
+	allLines := []coberturaLine{}
+	for _, pkg := range packages {
+		sort.Slice(pkg.Classes, func(i, j int) bool {
+			return pkg.Classes[i].Filename < pkg.Classes[j].Filename
+		})
+
+		pkgLines := []coberturaLine{}
+		for _, class := range pkg.Classes {
+			pkgLines = append(pkgLines, class.Lines...)
+		}
+		pkg.LineRate = lineRate(pkgLines)
+		allLines = append(allLines, pkgLines...)
+
+		report.Packages = append(report.Packages, *pkg)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Name < report.Packages[j].Name
+	})
+
+	report.LinesValid = len(allLines)
+	report.LinesCovered = coveredLines(allLines)
+	report.LineRate = lineRate(allLines)
+
+	var str strings.Builder
+	str.WriteString(xml.Header)
+	str.WriteString(`<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">` + "\n")
+
+	encoder := xml.NewEncoder(&str)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(report); err != nil {
+		return "", err
+	}
+	str.WriteString("\n")
+
+	return str.String(), nil
+}
+
+func coveredLines(lines []coberturaLine) int {
+	covered := 0
+	for _, line := range lines {
+		if line.Hits > 0 {
+			covered++
+		}
+	}
+
+	return covered
+}
+
+func lineRate(lines []coberturaLine) float64 {
+	if len(lines) == 0 {
+		return 0
+	}
+
+	return float64(coveredLines(lines)) / float64(len(lines))
+}
@@ -0,0 +1,135 @@
+package out
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var coberturaTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{
+			Path:      "example.com/mod/pkg/file1.go:3:",
+			Name:      "Function1",
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 3,
+			EndLine:   8,
+			Blocks: []cover.Block{
+				{StartLine: 3, EndLine: 5, NumStmt: 2, Count: 1},
+				{StartLine: 7, EndLine: 7, NumStmt: 1, Count: 0},
+			},
+		},
+		{
+			Path:      "example.com/mod/pkg/file1.go:10:",
+			Name:      "Function2",
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 10,
+			EndLine:   12,
+			Blocks: []cover.Block{
+				{StartLine: 10, EndLine: 12, NumStmt: 1, Count: 1},
+			},
+		},
+		{
+			Path:    "example.com/mod/sub/file2.go:5:",
+			Name:    "Function3",
+			Package: "example.com/mod/sub",
+		},
+	},
+	ModuleDir: "/src/mod",
+}
+
+func TestOutputCobertura(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.xml")
+	require.NoError(t, OutputCobertura(path, coberturaTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var got coberturaCoverage
+	require.NoError(t, xml.Unmarshal(gotBytes, &got))
+	assert.Len(t, got.Packages, 2)
+}
+
+func TestFormatCobertura(t *testing.T) {
+	got, err := formatCobertura(coberturaTestCoverage, time.UnixMilli(1700000000000))
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.8571428571428571" branch-rate="0" lines-covered="6" lines-valid="7" branches-covered="0" branches-valid="0" complexity="0" version="" timestamp="1700000000000">
+	<sources>
+		<source>/src/mod</source>
+	</sources>
+	<packages>
+		<package name="example.com/mod/pkg" line-rate="0.8571428571428571" branch-rate="0" complexity="0">
+			<classes>
+				<class name="pkg/file1.go" filename="pkg/file1.go" line-rate="0.8571428571428571" branch-rate="0" complexity="0">
+					<methods>
+						<method name="Function1" signature="" line-rate="0.75" branch-rate="0" complexity="0">
+							<lines>
+								<line number="3" hits="1" branch="false"></line>
+								<line number="4" hits="1" branch="false"></line>
+								<line number="5" hits="1" branch="false"></line>
+								<line number="7" hits="0" branch="false"></line>
+							</lines>
+						</method>
+						<method name="Function2" signature="" line-rate="1" branch-rate="0" complexity="0">
+							<lines>
+								<line number="10" hits="1" branch="false"></line>
+								<line number="11" hits="1" branch="false"></line>
+								<line number="12" hits="1" branch="false"></line>
+							</lines>
+						</method>
+					</methods>
+					<lines>
+						<line number="3" hits="1" branch="false"></line>
+						<line number="4" hits="1" branch="false"></line>
+						<line number="5" hits="1" branch="false"></line>
+						<line number="7" hits="0" branch="false"></line>
+						<line number="10" hits="1" branch="false"></line>
+						<line number="11" hits="1" branch="false"></line>
+						<line number="12" hits="1" branch="false"></line>
+					</lines>
+				</class>
+			</classes>
+		</package>
+		<package name="example.com/mod/sub" line-rate="0" branch-rate="0" complexity="0">
+			<classes>
+				<class name="example.com/mod/sub/file2.go" filename="example.com/mod/sub/file2.go" line-rate="0" branch-rate="0" complexity="0">
+					<methods>
+						<method name="Function3" signature="" line-rate="0" branch-rate="0" complexity="0">
+							<lines></lines>
+						</method>
+					</methods>
+					<lines></lines>
+				</class>
+			</classes>
+		</package>
+	</packages>
+</coverage>
+`
+	assert.Equal(t, expected, got)
+}
+
+func TestFormatCoberturaMethods(t *testing.T) {
+	coverage := cover.Result{
+		Coverage: []cover.Coverage{
+			{Name: "String", Receiver: "A", Package: "example.com/mod/pkg", File: "/src/mod/pkg/file.go", StartLine: 13, EndLine: 15},
+			{Name: "String", Receiver: "*B", Package: "example.com/mod/pkg", File: "/src/mod/pkg/file.go", StartLine: 17, EndLine: 19},
+		},
+		ModuleDir: "/src/mod",
+	}
+
+	got, err := formatCobertura(coverage, time.UnixMilli(1700000000000))
+	require.NoError(t, err)
+	assert.Contains(t, got, `<method name="A.String" signature=""`)
+	assert.Contains(t, got, `<method name="(*B).String" signature=""`)
+}
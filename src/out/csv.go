@@ -0,0 +1,75 @@
+package out
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+var csvHeader = []string{
+	"package",
+	"receiver",
+	"function",
+	"file",
+	"start_line",
+	"end_line",
+	"statements",
+	"covered_statements",
+	"coverage",
+	"tests",
+}
+
+func OutputCSV(path string, coverage cover.Result) error {
+	report, err := formatDelimited(coverage, ',')
+	if err != nil {
+		return fmt.Errorf("failed to format csv report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+func OutputTSV(path string, coverage cover.Result) error {
+	report, err := formatDelimited(coverage, '\t')
+	if err != nil {
+		return fmt.Errorf("failed to format tsv report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+func formatDelimited(coverage cover.Result, comma rune) (string, error) {
+	var str strings.Builder
+	writer := csv.NewWriter(&str)
+	writer.Comma = comma
+
+	records := [][]string{csvHeader}
+	for _, funcCoverage := range coverage.Coverage {
+		records = append(records, []string{
+			funcCoverage.Package,
+			funcCoverage.Receiver,
+			funcCoverage.Name,
+			relativeFile(coverage.ModuleDir, funcCoverage),
+			strconv.Itoa(funcCoverage.StartLine),
+			strconv.Itoa(funcCoverage.EndLine),
+			strconv.Itoa(funcCoverage.Statements),
+			strconv.Itoa(coveredStatements(funcCoverage)),
+			strconv.FormatFloat(funcCoverage.Coverage, 'f', 2, 64),
+			strings.Join(testNames(funcCoverage.Tests), " "),
+		})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return "", err
+	}
+
+	return str.String(), nil
+}
+
+// coveredStatements matches the weighting used for the approximate total.
+func coveredStatements(funcCoverage cover.Coverage) int {
+	return int(math.Round(float64(funcCoverage.Statements) * funcCoverage.Coverage / 100))
+}
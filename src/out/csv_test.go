@@ -0,0 +1,94 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var csvTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{
+			Path:       "example.com/mod/pkg/file1.go:3:",
+			Name:       "Function1",
+			Statements: 4,
+			Coverage:   75,
+			Package:    "example.com/mod/pkg",
+			File:       "/src/mod/pkg/file1.go",
+			StartLine:  3,
+			EndLine:    8,
+			Tests:      []cover.Function{{Package: "example.com/mod/pkg", Name: "TestFunction1"}, {Package: "example.com/mod/pkg", Name: "TestFunction2"}},
+		},
+		{
+			Path:       "example.com/mod/pkg/file1.go:10:",
+			Name:       "Method",
+			Statements: 3,
+			Coverage:   66.7,
+			Package:    "example.com/mod/pkg",
+			Receiver:   "*Struct",
+			File:       "/src/mod/pkg/file,1.go",
+			StartLine:  10,
+			EndLine:    14,
+		},
+	},
+	ModuleDir: "/src/mod",
+}
+
+func TestOutputCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.csv")
+	require.NoError(t, OutputCSV(path, csvTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	expected, err := formatDelimited(csvTestCoverage, ',')
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(gotBytes))
+}
+
+func TestOutputTSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.tsv")
+	require.NoError(t, OutputTSV(path, csvTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	expected, err := formatDelimited(csvTestCoverage, '\t')
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(gotBytes))
+}
+
+func TestFormatDelimited(t *testing.T) {
+	tests := []struct {
+		name     string
+		comma    rune
+		expected string
+	}{
+		{
+			name:  "csv",
+			comma: ',',
+			expected: "package,receiver,function,file,start_line,end_line,statements,covered_statements,coverage,tests\n" +
+				"example.com/mod/pkg,,Function1,pkg/file1.go,3,8,4,3,75.00,TestFunction1 TestFunction2\n" +
+				"example.com/mod/pkg,*Struct,Method,\"pkg/file,1.go\",10,14,3,2,66.70,\n",
+		},
+		{
+			name:  "tsv",
+			comma: '\t',
+			expected: "package\treceiver\tfunction\tfile\tstart_line\tend_line\tstatements\tcovered_statements\tcoverage\ttests\n" +
+				"example.com/mod/pkg\t\tFunction1\tpkg/file1.go\t3\t8\t4\t3\t75.00\tTestFunction1 TestFunction2\n" +
+				"example.com/mod/pkg\t*Struct\tMethod\tpkg/file,1.go\t10\t14\t3\t2\t66.70\t\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatDelimited(csvTestCoverage, tt.comma)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package out
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+// OutputDelta prints the change in coverage from previous to current.
+func OutputDelta(previous, current cover.Result) {
+	fmt.Println(formatCoverageDelta(previous, current))
+}
+
+func formatCoverageDelta(previous, current cover.Result) string {
+	before := map[cover.Function]cover.Coverage{}
+	for _, funcCoverage := range previous.Coverage {
+		before[coverageFunction(funcCoverage)] = funcCoverage
+	}
+	after := map[cover.Function]cover.Coverage{}
+	for _, funcCoverage := range current.Coverage {
+		after[coverageFunction(funcCoverage)] = funcCoverage
+	}
+
+	var str strings.Builder
+	str.WriteString(fmt.Sprintf("Total: %.2f%% (%s)\n", current.ApproxTotalCoverage, formatDelta(current.ApproxTotalCoverage-previous.ApproxTotalCoverage)))
+
+	for _, funcCoverage := range current.Coverage {
+		fn := coverageFunction(funcCoverage)
+		previousCoverage, ok := before[fn]
+		switch {
+		case !ok:
+			str.WriteString(fmt.Sprintf("  new      %s.%s %.1f%%\n", fn.Package, functionName(fn), funcCoverage.Coverage))
+		case previousCoverage.Coverage != funcCoverage.Coverage:
+			str.WriteString(fmt.Sprintf("  %-8s %s.%s %.1f%% -> %.1f%%\n",
+				formatDelta(funcCoverage.Coverage-previousCoverage.Coverage),
+				fn.Package,
+				functionName(fn),
+				previousCoverage.Coverage,
+				funcCoverage.Coverage))
+		}
+	}
+
+	for _, funcCoverage := range previous.Coverage {
+		fn := coverageFunction(funcCoverage)
+		if _, ok := after[fn]; !ok {
+			str.WriteString(fmt.Sprintf("  removed  %s.%s\n", fn.Package, functionName(fn)))
+		}
+	}
+
+	return strings.TrimSuffix(str.String(), "\n")
+}
@@ -0,0 +1,61 @@
+package out
+
+import (
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputDelta(t *testing.T) {
+	OutputDelta(cover.Result{}, cover.Result{})
+}
+
+func TestFormatCoverageDelta(t *testing.T) {
+	tests := []struct {
+		name           string
+		previous       cover.Result
+		current        cover.Result
+		expectedResult string
+	}{
+		{
+			name: "unchanged",
+			previous: cover.Result{
+				Coverage:            []cover.Coverage{{Package: "pkg", Name: "Top", Coverage: 50}},
+				ApproxTotalCoverage: 50,
+			},
+			current: cover.Result{
+				Coverage:            []cover.Coverage{{Package: "pkg", Name: "Top", Coverage: 50}},
+				ApproxTotalCoverage: 50,
+			},
+			expectedResult: "Total: 50.00% (+0.0%)",
+		},
+		{
+			name: "changed, new and removed functions",
+			previous: cover.Result{
+				Coverage: []cover.Coverage{
+					{Package: "pkg", Name: "Top", Coverage: 50},
+					{Package: "pkg", Name: "Bottom", Coverage: 100},
+				},
+				ApproxTotalCoverage: 75,
+			},
+			current: cover.Result{
+				Coverage: []cover.Coverage{
+					{Package: "pkg", Name: "Top", Coverage: 75},
+					{Package: "pkg/sub", Name: "Sub", Coverage: 0},
+				},
+				ApproxTotalCoverage: 60,
+			},
+			expectedResult: "Total: 60.00% (-15.0%)\n" +
+				"  +25.0%   pkg.Top 50.0% -> 75.0%\n" +
+				"  new      pkg/sub.Sub 0.0%\n" +
+				"  removed  pkg.Bottom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, formatCoverageDelta(tt.previous, tt.current))
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package out
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/leobishop234/deepcover/src/cover"
@@ -11,13 +13,29 @@ import (
 const coverageFormat = "%s\t\t%s\t\t%.2f%%\n"
 
 func OutputFile(path string, coverage cover.Result) error {
+	return writeFile(path, formatFile(coverage))
+}
+
+// writeOutput writes content to path, or to the terminal if path is empty.
+func writeOutput(path, content string) error {
+	if path == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	return writeFile(path, content)
+}
+
+func writeFile(path, content string) error {
 	coverageFile, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create coverage file: %v", err)
 	}
 	defer coverageFile.Close()
 
-	coverageFile.WriteString(formatFile(coverage))
+	if _, err := coverageFile.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write coverage file: %v", err)
+	}
 	return nil
 }
 
@@ -31,3 +49,67 @@ func formatFile(coverage cover.Result) string {
 
 	return str.String()
 }
+
+// ReadFile parses a coverage file written by OutputFile or OutputJSON, for
+// example to use as a baseline. Only the name, path and coverage of each
+// function are recovered from a file written by OutputFile.
+func ReadFile(path string) (cover.Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cover.Result{}, fmt.Errorf("failed to read coverage file: %v", err)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+		var result cover.Result
+		if err := json.Unmarshal(content, &result); err != nil {
+			return cover.Result{}, fmt.Errorf("failed to parse json coverage file: %v", err)
+		}
+		return result, nil
+	}
+
+	return parseFile(string(content))
+}
+
+func parseFile(content string) (cover.Result, error) {
+	result := cover.Result{Coverage: []cover.Coverage{}}
+	for _, row := range strings.Split(content, "\n") {
+		if row == "" {
+			continue
+		}
+
+		if total, ok := strings.CutPrefix(row, "Total: "); ok {
+			coverage, err := parsePercentage(total)
+			if err != nil {
+				return cover.Result{}, err
+			}
+			result.ApproxTotalCoverage = coverage
+			continue
+		}
+
+		parts := strings.Split(row, "\t\t")
+		if len(parts) != 3 {
+			return cover.Result{}, fmt.Errorf("invalid coverage row %q", row)
+		}
+
+		coverage, err := parsePercentage(parts[2])
+		if err != nil {
+			return cover.Result{}, err
+		}
+		result.Coverage = append(result.Coverage, cover.Coverage{
+			Name:     parts[0],
+			Path:     parts[1],
+			Coverage: coverage,
+		})
+	}
+
+	return result, nil
+}
+
+func parsePercentage(s string) (float64, error) {
+	coverage, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid coverage percentage %q: %w", s, err)
+	}
+
+	return coverage, nil
+}
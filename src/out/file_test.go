@@ -2,6 +2,7 @@ package out
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/leobishop234/deepcover/src/cover"
@@ -53,3 +54,79 @@ Total: 50.00%
 
 	assert.Equal(t, expected, got)
 }
+
+func TestReadFile(t *testing.T) {
+	temp, err := os.CreateTemp(t.TempDir(), "*.coverage")
+	require.NoError(t, err)
+	defer temp.Close()
+
+	require.NoError(t, OutputFile(temp.Name(), fileTestCoverage))
+
+	got, err := ReadFile(temp.Name())
+	require.NoError(t, err)
+	assert.Equal(t, fileTestCoverage, got)
+}
+
+func TestReadFileJSON(t *testing.T) {
+	coverage := cover.Result{
+		Coverage: []cover.Coverage{
+			{Path: "example.com/mod/pkg/file1.go:3:", Name: "String", Receiver: "*B", Package: "example.com/mod/pkg", Coverage: 50},
+		},
+		ApproxTotalCoverage: 50,
+	}
+
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	require.NoError(t, OutputJSON(path, coverage))
+
+	got, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, coverage, got)
+}
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    cover.Result
+		expectError bool
+	}{
+		{
+			name:     "empty file",
+			content:  "",
+			expected: cover.Result{Coverage: []cover.Coverage{}},
+		},
+		{
+			name:    "rows and total",
+			content: "Top\t\texample.com/pkg/file.go:5:\t\t75.50%\nTotal: 75.50%\n",
+			expected: cover.Result{
+				Coverage: []cover.Coverage{
+					{Name: "Top", Path: "example.com/pkg/file.go:5:", Coverage: 75.5},
+				},
+				ApproxTotalCoverage: 75.5,
+			},
+		},
+		{
+			name:        "malformed row",
+			content:     "Top example.com/pkg/file.go:5: 75.50%\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid percentage",
+			content:     "Top\t\texample.com/pkg/file.go:5:\t\tabc%\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFile(tt.content)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package out
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+// OutputGitHub prints a workflow command annotation for each dependency below
+// the threshold and appends a Markdown summary to path, which defaults to
+// $GITHUB_STEP_SUMMARY.
+func OutputGitHub(path string, coverage cover.Result, threshold float64) error {
+	fmt.Print(formatGitHubAnnotations(coverage, threshold, os.Getenv("GITHUB_WORKSPACE")))
+
+	if path == "" {
+		path = os.Getenv("GITHUB_STEP_SUMMARY")
+	}
+	if path == "" {
+		return nil
+	}
+
+	summaryFile, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %v", err)
+	}
+	defer summaryFile.Close()
+
+	if _, err := summaryFile.WriteString(formatGitHubSummary(coverage)); err != nil {
+		return fmt.Errorf("failed to write step summary: %v", err)
+	}
+	return nil
+}
+
+func formatGitHubAnnotations(coverage cover.Result, threshold float64, workspace string) string {
+	baseDir := workspace
+	if baseDir == "" {
+		baseDir = coverage.ModuleDir
+	}
+
+	var str strings.Builder
+	for _, funcCoverage := range belowThreshold(coverage.Coverage, threshold) {
+		properties := []string{"file=" + escapeGitHubProperty(relativeFile(baseDir, funcCoverage))}
+		if funcCoverage.StartLine > 0 {
+			properties = append(properties,
+				fmt.Sprintf("line=%d", funcCoverage.StartLine),
+				fmt.Sprintf("endLine=%d", funcCoverage.EndLine),
+			)
+		}
+		properties = append(properties, "title="+escapeGitHubProperty("deepcover: "+functionName(funcCoverage.Function())))
+
+		str.WriteString(fmt.Sprintf("::warning %s::%s\n",
+			strings.Join(properties, ","),
+			escapeGitHubData(lowCoverageMessage(funcCoverage, threshold))))
+	}
+
+	return str.String()
+}
+
+func formatGitHubSummary(coverage cover.Result) string {
+	var str strings.Builder
+	str.WriteString("### Deepcover\n\n")
+	str.WriteString("| Function | Path | Coverage |\n")
+	str.WriteString("| --- | --- | ---: |\n")
+	for _, funcCoverage := range coverage.Coverage {
+		str.WriteString(fmt.Sprintf("| %s | `%s` | %.1f%% |\n", escapeMarkdown(functionName(funcCoverage.Function())), funcCoverage.Path, funcCoverage.Coverage))
+	}
+	str.WriteString(fmt.Sprintf("\n**Total: %.2f%%**\n\n", coverage.ApproxTotalCoverage))
+
+	return str.String()
+}
+
+var githubDataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+var githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+func escapeGitHubData(s string) string {
+	return githubDataEscaper.Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return githubPropertyEscaper.Replace(s)
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
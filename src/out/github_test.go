@@ -0,0 +1,116 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var githubTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{
+			Path:      "example.com/mod/pkg/file1.go:3:",
+			Name:      "Function1",
+			Coverage:  100,
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 3,
+			EndLine:   8,
+		},
+		{
+			Path:      "example.com/mod/pkg/file1.go:10:",
+			Name:      "Function2",
+			Receiver:  "*Store",
+			Coverage:  50,
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 10,
+			EndLine:   14,
+			Tests:     []cover.Function{{Package: "example.com/mod/pkg", Name: "TestFunction1"}, {Package: "example.com/mod/pkg", Name: "TestFunction2"}},
+		},
+		{
+			Path:     "example.com/mod/sub/file2.go:5:",
+			Name:     "Function_3",
+			Coverage: 0,
+			Package:  "example.com/mod/sub",
+		},
+	},
+	ApproxTotalCoverage: 62.5,
+	ModuleDir:           "/src/mod",
+}
+
+func TestOutputGitHub(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o644))
+
+	require.NoError(t, OutputGitHub(path, githubTestCoverage, 80))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "existing\n"+formatGitHubSummary(githubTestCoverage), string(gotBytes))
+}
+
+func TestOutputGitHubStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "step_summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	require.NoError(t, OutputGitHub("", githubTestCoverage, 80))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, formatGitHubSummary(githubTestCoverage), string(gotBytes))
+}
+
+func TestFormatGitHubAnnotations(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		workspace string
+		expected  string
+	}{
+		{
+			name:      "nothing below threshold",
+			threshold: 0,
+			expected:  "",
+		},
+		{
+			name:      "paths relative to module",
+			threshold: 80,
+			expected: "::warning file=pkg/file1.go,line=10,endLine=14,title=deepcover%3A (*Store).Function2::(*Store).Function2 is 50.0%25 covered, below the 80.0%25 threshold; reached statically by TestFunction1, TestFunction2\n" +
+				"::warning file=example.com/mod/sub/file2.go,title=deepcover%3A Function_3::Function_3 is 0.0%25 covered, below the 80.0%25 threshold\n",
+		},
+		{
+			name:      "paths relative to workspace",
+			threshold: 60,
+			workspace: "/src",
+			expected:  "::warning file=mod/pkg/file1.go,line=10,endLine=14,title=deepcover%3A (*Store).Function2::(*Store).Function2 is 50.0%25 covered, below the 60.0%25 threshold; reached statically by TestFunction1, TestFunction2\n::warning file=example.com/mod/sub/file2.go,title=deepcover%3A Function_3::Function_3 is 0.0%25 covered, below the 60.0%25 threshold\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatGitHubAnnotations(githubTestCoverage, tt.threshold, tt.workspace))
+		})
+	}
+}
+
+func TestFormatGitHubSummary(t *testing.T) {
+	expected := "### Deepcover\n\n" +
+		"| Function | Path | Coverage |\n" +
+		"| --- | --- | ---: |\n" +
+		"| Function1 | `example.com/mod/pkg/file1.go:3:` | 100.0% |\n" +
+		"| (\\*Store).Function2 | `example.com/mod/pkg/file1.go:10:` | 50.0% |\n" +
+		"| Function\\_3 | `example.com/mod/sub/file2.go:5:` | 0.0% |\n" +
+		"\n**Total: 62.50%**\n\n"
+
+	assert.Equal(t, expected, formatGitHubSummary(githubTestCoverage))
+}
+
+func TestEscapeGitHub(t *testing.T) {
+	assert.Equal(t, "100%25%0Anext", escapeGitHubData("100%\nnext"))
+	assert.Equal(t, "a%3Ab%2Cc%0D", escapeGitHubProperty("a:b,c\r"))
+}
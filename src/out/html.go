@@ -0,0 +1,137 @@
+package out
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+func OutputHTML(path string, coverage cover.Result) error {
+	report, err := formatHTML(coverage)
+	if err != nil {
+		return fmt.Errorf("failed to format html report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+type htmlReport struct {
+	Total     float64
+	Packages  []cover.PackageCoverage
+	Functions []htmlFunction
+	Tests     []*callNode
+}
+
+type htmlFunction struct {
+	cover.Coverage
+	Anchor string
+	Source []sourceLine
+	Error  string
+}
+
+func formatHTML(coverage cover.Result) (string, error) {
+	report := htmlReport{
+		Total:    coverage.ApproxTotalCoverage,
+		Packages: cover.Packages(coverage.Coverage),
+	}
+
+	anchors := make(map[cover.Function]string, len(coverage.Coverage))
+	for i, funcCoverage := range coverage.Coverage {
+		anchor := fmt.Sprintf("fn-%d", i)
+		anchors[funcCoverage.Function()] = anchor
+
+		source, err := annotateSource(funcCoverage)
+		function := htmlFunction{Coverage: funcCoverage, Anchor: anchor, Source: source}
+		if err != nil {
+			function.Error = err.Error()
+		}
+		report.Functions = append(report.Functions, function)
+	}
+
+	for _, test := range coverage.Tests {
+		report.Tests = append(report.Tests, buildCallTree(test, coverage.Coverage))
+	}
+
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"anchor": func(fn cover.Function) string { return anchors[fn] },
+		"name":   functionName,
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var str strings.Builder
+	if err := tmpl.Execute(&str, report); err != nil {
+		return "", err
+	}
+
+	return str.String(), nil
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>deepcover report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+th, td { padding: 2px 12px; text-align: left; }
+th { border-bottom: 1px solid #999; }
+pre { background: #f6f6f6; padding: 8px; overflow-x: auto; }
+.lineno { color: #999; display: inline-block; width: 4em; }
+.covered { background: #d4f4d4; }
+.uncovered { background: #f8d0d0; }
+.partial { background: #f8f0c0; }
+.marker { color: #999; font-style: italic; }
+ul.tree { list-style: none; padding-left: 1.5em; }
+</style>
+</head>
+<body>
+<h1>deepcover report</h1>
+<p>Total: {{printf "%.2f%%" .Total}}</p>
+
+<h2>Packages</h2>
+<table>
+<tr><th>PACKAGE</th><th>FUNCTIONS</th><th>STATEMENTS</th><th>COVERAGE</th></tr>
+{{- range .Packages}}
+<tr><td>{{.Package}}</td><td>{{.Functions}}</td><td>{{.Statements}}</td><td>{{printf "%.1f%%" .Coverage}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Tests</h2>
+{{- range .Tests}}
+<ul class="tree">{{template "node" .}}</ul>
+{{- end}}
+
+<h2>Functions</h2>
+{{- range .Functions}}
+<h3 id="{{.Anchor}}">{{.Name}} <small>{{.Path}} {{printf "%.1f%%" .Coverage.Coverage}}</small></h3>
+{{- if .Error}}
+<p class="marker">{{.Error}}</p>
+{{- else}}
+<pre>
+{{- range .Source}}
+<span class="{{.Class}}"><span class="lineno">{{.Number}}</span>{{.Text}}</span>
+{{- end}}
+</pre>
+{{- end}}
+{{- end}}
+</body>
+</html>
+{{define "node" -}}
+<li>
+{{- with anchor .Function}}<a href="#{{.}}">{{end}}{{.Function.Package}}.{{name .Function}}{{with anchor .Function}}</a>{{end}}
+{{- with .Coverage}} {{printf "%.1f%%" .Coverage}}{{end}}
+{{- if .Setup}} <span class="marker">(shared setup)</span>{{end}}
+{{- if .Cycle}} <span class="marker">(cycle)</span>{{end}}
+{{- if .Shared}} <span class="marker">(shown above)</span>{{end}}
+{{- if .Children}}
+<ul class="tree">
+{{- range .Children}}{{template "node" .}}{{end}}
+</ul>
+{{- end -}}
+</li>
+{{- end}}`
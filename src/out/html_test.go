@@ -0,0 +1,141 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const htmlTestSource = `package example
+
+func Function1(x int) int {
+	if x > 0 {
+		return x
+	}
+	return -x
+}
+`
+
+func htmlTestCoverage(t *testing.T) cover.Result {
+	source := filepath.Join(t.TempDir(), "example.go")
+	require.NoError(t, os.WriteFile(source, []byte(htmlTestSource), 0o644))
+
+	return cover.Result{
+		Coverage: []cover.Coverage{
+			{
+				Path:       "example/path/example.go:3:",
+				Name:       "Function1",
+				Statements: 3,
+				Coverage:   66.7,
+				Package:    "example/path",
+				File:       source,
+				StartLine:  3,
+				EndLine:    8,
+				Blocks: []cover.Block{
+					{StartLine: 3, StartCol: 28, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 1},
+					{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 1},
+					{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 11, NumStmt: 1, Count: 0},
+				},
+			},
+			{
+				Path:     "example/path/other.go:10:",
+				Name:     "Function2",
+				Coverage: 0,
+				Package:  "example/path",
+			},
+		},
+		ApproxTotalCoverage: 66.7,
+		Tests: []cover.Test{
+			{
+				Function: cover.Function{Package: "example/path", Name: "TestFunction1"},
+				Calls: []cover.Call{
+					{
+						Caller: cover.Function{Package: "example/path", Name: "TestFunction1"},
+						Callee: cover.Function{Package: "example/path", Name: "Function1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestOutputHTML(t *testing.T) {
+	coverage := htmlTestCoverage(t)
+	path := filepath.Join(t.TempDir(), "coverage.html")
+
+	require.NoError(t, OutputHTML(path, coverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	expected, err := formatHTML(coverage)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(gotBytes))
+}
+
+func TestFormatHTML(t *testing.T) {
+	got, err := formatHTML(htmlTestCoverage(t))
+	require.NoError(t, err)
+
+	assert.Contains(t, got, "Total: 66.70%")
+	assert.Contains(t, got, "<td>example/path</td><td>2</td><td>3</td><td>66.7%</td>")
+
+	assert.Contains(t, got, `<h3 id="fn-0">Function1`)
+	assert.Contains(t, got, `<span class="covered"><span class="lineno">5</span>		return x</span>`)
+	assert.Contains(t, got, `<span class="uncovered"><span class="lineno">7</span>	return -x</span>`)
+	assert.Contains(t, got, `<span class=""><span class="lineno">8</span>}</span>`)
+
+	assert.Contains(t, got, `<h3 id="fn-1">Function2`)
+	assert.Contains(t, got, "source location unknown")
+
+	assert.Contains(t, got, "<li>example/path.TestFunction1")
+	assert.Contains(t, got, `<li><a href="#fn-0">example/path.Function1</a> 66.7%</li>`)
+}
+
+func TestFormatHTMLMethods(t *testing.T) {
+	test := cover.Function{Package: "example/path", Name: "TestString"}
+	got, err := formatHTML(cover.Result{
+		Coverage: []cover.Coverage{
+			{Path: "example/path/a.go:3:", Name: "String", Receiver: "A", Package: "example/path", Coverage: 100},
+			{Path: "example/path/b.go:3:", Name: "String", Receiver: "*B", Package: "example/path", Coverage: 0},
+		},
+		Tests: []cover.Test{
+			{
+				Function: test,
+				Calls: []cover.Call{
+					{Caller: test, Callee: cover.Function{Package: "example/path", Receiver: "A", Name: "String"}},
+					{Caller: test, Callee: cover.Function{Package: "example/path", Receiver: "*B", Name: "String"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, got, `<li><a href="#fn-0">example/path.A.String</a> 100.0%</li>`)
+	assert.Contains(t, got, `<li><a href="#fn-1">example/path.(*B).String</a> 0.0%</li>`)
+}
+
+func TestFormatHTMLTestMain(t *testing.T) {
+	test := cover.Function{Package: "example/path", Name: "TestTop"}
+	testMain := cover.Function{Package: "example/path", Name: "TestMain"}
+	got, err := formatHTML(cover.Result{
+		Coverage: []cover.Coverage{
+			{Path: "example/path/server.go:3:", Name: "startServer", Package: "example/path", Coverage: 100, Setup: true},
+		},
+		Tests: []cover.Test{
+			{
+				Function: test,
+				Calls:    []cover.Call{{Caller: testMain, Callee: cover.Function{Package: "example/path", Name: "startServer"}}},
+				TestMain: &testMain,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, got, `<li>example/path.TestMain <span class="marker">(shared setup)</span>`)
+	assert.Contains(t, got, `<li><a href="#fn-0">example/path.startServer</a> 100.0% <span class="marker">(shared setup)</span></li>`)
+}
@@ -0,0 +1,26 @@
+package out
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+func OutputJSON(path string, coverage cover.Result) error {
+	report, err := formatJSON(coverage)
+	if err != nil {
+		return fmt.Errorf("failed to format json report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+func formatJSON(coverage cover.Result) (string, error) {
+	report, err := json.MarshalIndent(coverage, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(report) + "\n", nil
+}
@@ -0,0 +1,33 @@
+package out
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	require.NoError(t, OutputJSON(path, templateTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	expected, err := formatJSON(templateTestCoverage)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(gotBytes))
+}
+
+func TestFormatJSON(t *testing.T) {
+	got, err := formatJSON(templateTestCoverage)
+	require.NoError(t, err)
+
+	var decoded cover.Result
+	require.NoError(t, json.Unmarshal([]byte(got), &decoded))
+	assert.Equal(t, templateTestCoverage, decoded)
+}
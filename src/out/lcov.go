@@ -0,0 +1,89 @@
+package out
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+func OutputLCOV(path string, coverage cover.Result) error {
+	return writeOutput(path, formatLCOV(coverage))
+}
+
+func formatLCOV(coverage cover.Result) string {
+	files := map[string][]cover.Coverage{}
+	for _, funcCoverage := range coverage.Coverage {
+		file := relativeFile(coverage.ModuleDir, funcCoverage)
+		files[file] = append(files[file], funcCoverage)
+	}
+
+	names := make([]string, 0, len(files))
+	for file := range files {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+
+	var str strings.Builder
+	for _, file := range names {
+		functions := files[file]
+		sort.SliceStable(functions, func(i, j int) bool {
+			return functions[i].StartLine < functions[j].StartLine
+		})
+
+		str.WriteString("TN:\n")
+		str.WriteString(fmt.Sprintf("SF:%s\n", file))
+
+		functionsHit := 0
+		for _, funcCoverage := range functions {
+			name := functionName(funcCoverage.Function())
+			if funcCoverage.EndLine >= funcCoverage.StartLine && funcCoverage.StartLine > 0 {
+				str.WriteString(fmt.Sprintf("FN:%d,%d,%s\n", funcCoverage.StartLine, funcCoverage.EndLine, name))
+			} else {
+				str.WriteString(fmt.Sprintf("FN:%d,%s\n", funcCoverage.StartLine, name))
+			}
+		}
+		for _, funcCoverage := range functions {
+			hits := functionHits(funcCoverage)
+			if hits > 0 {
+				functionsHit++
+			}
+			str.WriteString(fmt.Sprintf("FNDA:%d,%s\n", hits, functionName(funcCoverage.Function())))
+		}
+		str.WriteString(fmt.Sprintf("FNF:%d\n", len(functions)))
+		str.WriteString(fmt.Sprintf("FNH:%d\n", functionsHit))
+
+		linesFound, linesHit := 0, 0
+		for _, funcCoverage := range functions {
+			for _, line := range coverageLines(funcCoverage) {
+				linesFound++
+				if line.Count > 0 {
+					linesHit++
+				}
+				str.WriteString(fmt.Sprintf("DA:%d,%d\n", line.Line, line.Count))
+			}
+		}
+		str.WriteString(fmt.Sprintf("LF:%d\n", linesFound))
+		str.WriteString(fmt.Sprintf("LH:%d\n", linesHit))
+		str.WriteString("end_of_record\n")
+	}
+
+	return str.String()
+}
+
+// functionHits is the execution count of the function's entry block.
+func functionHits(funcCoverage cover.Coverage) int {
+	if len(funcCoverage.Blocks) == 0 {
+		return 0
+	}
+
+	entry := funcCoverage.Blocks[0]
+	for _, block := range funcCoverage.Blocks[1:] {
+		if block.StartLine < entry.StartLine || (block.StartLine == entry.StartLine && block.StartCol < entry.StartCol) {
+			entry = block
+		}
+	}
+
+	return entry.Count
+}
@@ -0,0 +1,153 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lcovTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{
+			Path:      "example.com/mod/pkg/file1.go:10:",
+			Name:      "Function2",
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 10,
+			EndLine:   12,
+			Blocks: []cover.Block{
+				{StartLine: 10, StartCol: 20, EndLine: 12, EndCol: 2, NumStmt: 1, Count: 0},
+			},
+		},
+		{
+			Path:      "example.com/mod/pkg/file1.go:3:",
+			Name:      "Function1",
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 3,
+			EndLine:   8,
+			Blocks: []cover.Block{
+				{StartLine: 3, StartCol: 20, EndLine: 5, EndCol: 3, NumStmt: 2, Count: 1},
+				{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, NumStmt: 1, Count: 0},
+			},
+		},
+		{
+			Path:      "example.com/mod/sub/file2.go:5:",
+			Name:      "Function3",
+			Package:   "example.com/mod/sub",
+			File:      "/src/mod/sub/file2.go",
+			StartLine: 5,
+			EndLine:   6,
+			Blocks: []cover.Block{
+				{StartLine: 5, StartCol: 15, EndLine: 6, EndCol: 2, NumStmt: 1, Count: 1},
+			},
+		},
+	},
+	ModuleDir: "/src/mod",
+}
+
+func TestOutputLCOV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lcov.info")
+	require.NoError(t, OutputLCOV(path, lcovTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, formatLCOV(lcovTestCoverage), string(gotBytes))
+}
+
+func TestFormatLCOV(t *testing.T) {
+	expected := `TN:
+SF:pkg/file1.go
+FN:3,8,Function1
+FN:10,12,Function2
+FNDA:1,Function1
+FNDA:0,Function2
+FNF:2
+FNH:1
+DA:3,1
+DA:4,1
+DA:5,1
+DA:7,0
+DA:10,0
+DA:11,0
+DA:12,0
+LF:7
+LH:3
+end_of_record
+TN:
+SF:sub/file2.go
+FN:5,6,Function3
+FNDA:1,Function3
+FNF:1
+FNH:1
+DA:5,1
+DA:6,1
+LF:2
+LH:2
+end_of_record
+`
+
+	assert.Equal(t, expected, formatLCOV(lcovTestCoverage))
+}
+
+func TestFormatLCOVMethods(t *testing.T) {
+	coverage := cover.Result{
+		Coverage: []cover.Coverage{
+			{
+				Name: "String", Receiver: "A", Package: "example.com/mod/pkg", File: "/src/mod/pkg/file.go",
+				StartLine: 13, EndLine: 15,
+				Blocks: []cover.Block{{StartLine: 13, StartCol: 20, EndLine: 15, EndCol: 2, NumStmt: 1, Count: 1}},
+			},
+			{
+				Name: "String", Receiver: "*B", Package: "example.com/mod/pkg", File: "/src/mod/pkg/file.go",
+				StartLine: 17, EndLine: 19,
+				Blocks: []cover.Block{{StartLine: 17, StartCol: 21, EndLine: 19, EndCol: 2, NumStmt: 1, Count: 0}},
+			},
+			{Name: "Unknown", Package: "example.com/mod/pkg", File: "/src/mod/pkg/file.go"},
+		},
+		ModuleDir: "/src/mod",
+	}
+
+	got := formatLCOV(coverage)
+	assert.Contains(t, got, "FN:0,Unknown\nFN:13,15,A.String\nFN:17,19,(*B).String\n")
+	assert.Contains(t, got, "FNDA:0,Unknown\nFNDA:1,A.String\nFNDA:0,(*B).String\n")
+}
+
+func TestFunctionHits(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []cover.Block
+		expected int
+	}{
+		{
+			name:     "no blocks",
+			expected: 0,
+		},
+		{
+			name: "entry block is first",
+			blocks: []cover.Block{
+				{StartLine: 3, StartCol: 10, Count: 4},
+				{StartLine: 5, StartCol: 2, Count: 0},
+			},
+			expected: 4,
+		},
+		{
+			name: "entry block is not first",
+			blocks: []cover.Block{
+				{StartLine: 5, StartCol: 2, Count: 0},
+				{StartLine: 3, StartCol: 10, Count: 2},
+			},
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, functionHits(cover.Coverage{Blocks: tt.blocks}))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package out
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+// lineCoverage is the execution count of a single source line, taken from
+// the coverprofile blocks that span it.
+type lineCoverage struct {
+	Line    int
+	Count   int
+	Partial bool
+}
+
+// sourceLine is a line of a function's source, classed as covered, uncovered
+// or partial, or unclassed if it holds no statements.
+type sourceLine struct {
+	Number int
+	Text   string
+	Class  string
+}
+
+func coverageLines(funcCoverage cover.Coverage) []lineCoverage {
+	byLine := map[int]*lineCoverage{}
+	for _, block := range funcCoverage.Blocks {
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			lc, ok := byLine[line]
+			if !ok {
+				byLine[line] = &lineCoverage{Line: line, Count: block.Count}
+				continue
+			}
+
+			if (lc.Count == 0) != (block.Count == 0) {
+				lc.Partial = true
+			}
+			if block.Count > lc.Count {
+				lc.Count = block.Count
+			}
+		}
+	}
+
+	lines := make([]lineCoverage, 0, len(byLine))
+	for _, lc := range byLine {
+		lines = append(lines, *lc)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Line < lines[j].Line
+	})
+
+	return lines
+}
+
+// relativeFile returns the path of the function's file relative to the module
+// root, falling back to the import path based file name from the profile.
+func relativeFile(moduleDir string, funcCoverage cover.Coverage) string {
+	if moduleDir != "" && funcCoverage.File != "" {
+		if rel, err := filepath.Rel(moduleDir, funcCoverage.File); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	fileName, _, _ := strings.Cut(funcCoverage.Path, ":")
+	return fileName
+}
+
+func annotateSource(funcCoverage cover.Coverage) ([]sourceLine, error) {
+	if funcCoverage.File == "" {
+		return nil, fmt.Errorf("source location unknown")
+	}
+
+	src, err := os.ReadFile(funcCoverage.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source: %v", err)
+	}
+
+	classes := map[int]string{}
+	for _, line := range coverageLines(funcCoverage) {
+		switch {
+		case line.Partial:
+			classes[line.Line] = "partial"
+		case line.Count > 0:
+			classes[line.Line] = "covered"
+		default:
+			classes[line.Line] = "uncovered"
+		}
+	}
+
+	lines := strings.Split(string(src), "\n")
+	annotated := []sourceLine{}
+	for number := funcCoverage.StartLine; number <= funcCoverage.EndLine && number <= len(lines); number++ {
+		annotated = append(annotated, sourceLine{
+			Number: number,
+			Text:   lines[number-1],
+			Class:  classes[number],
+		})
+	}
+
+	return annotated, nil
+}
@@ -0,0 +1,111 @@
+package out
+
+import (
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []cover.Block
+		expected []lineCoverage
+	}{
+		{
+			name:     "no blocks",
+			blocks:   nil,
+			expected: []lineCoverage{},
+		},
+		{
+			name: "single covered block",
+			blocks: []cover.Block{
+				{StartLine: 5, EndLine: 7, NumStmt: 1, Count: 1},
+			},
+			expected: []lineCoverage{
+				{Line: 5, Count: 1},
+				{Line: 6, Count: 1},
+				{Line: 7, Count: 1},
+			},
+		},
+		{
+			name: "covered and uncovered blocks sharing a line",
+			blocks: []cover.Block{
+				{StartLine: 5, EndLine: 6, NumStmt: 1, Count: 1},
+				{StartLine: 6, EndLine: 8, NumStmt: 2, Count: 0},
+			},
+			expected: []lineCoverage{
+				{Line: 5, Count: 1},
+				{Line: 6, Count: 1, Partial: true},
+				{Line: 7, Count: 0},
+				{Line: 8, Count: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := coverageLines(cover.Coverage{Blocks: tt.blocks})
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestRelativeFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		moduleDir    string
+		funcCoverage cover.Coverage
+		expected     string
+	}{
+		{
+			name:         "file within module",
+			moduleDir:    "/src/mod",
+			funcCoverage: cover.Coverage{Path: "example.com/mod/pkg/file.go:3:", File: "/src/mod/pkg/file.go"},
+			expected:     "pkg/file.go",
+		},
+		{
+			name:         "file outside module",
+			moduleDir:    "/src/mod",
+			funcCoverage: cover.Coverage{Path: "example.com/other/file.go:3:", File: "/src/other/file.go"},
+			expected:     "example.com/other/file.go",
+		},
+		{
+			name:         "unknown module directory",
+			funcCoverage: cover.Coverage{Path: "example.com/mod/pkg/file.go:3:", File: "/src/mod/pkg/file.go"},
+			expected:     "example.com/mod/pkg/file.go",
+		},
+		{
+			name:         "unknown file",
+			moduleDir:    "/src/mod",
+			funcCoverage: cover.Coverage{Path: "example.com/mod/pkg/file.go:3:"},
+			expected:     "example.com/mod/pkg/file.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, relativeFile(tt.moduleDir, tt.funcCoverage))
+		})
+	}
+}
+
+func TestAnnotateSource(t *testing.T) {
+	coverage := htmlTestCoverage(t)
+
+	lines, err := annotateSource(coverage.Coverage[0])
+	require.NoError(t, err)
+	require.Len(t, lines, 6)
+
+	classes := []string{}
+	for _, line := range lines {
+		classes = append(classes, line.Class)
+	}
+	assert.Equal(t, []string{"covered", "covered", "covered", "covered", "uncovered", ""}, classes)
+	assert.Equal(t, "func Function1(x int) int {", lines[0].Text)
+
+	_, err = annotateSource(coverage.Coverage[1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package out
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+const worstCoveredFunctions = 5
+
+// OutputMarkdown writes a Markdown report suitable for pull request comments.
+// If baseline is not nil, coverage deltas against it are included.
+func OutputMarkdown(path string, coverage cover.Result, baseline *cover.Result) error {
+	return writeOutput(path, formatMarkdown(coverage, baseline))
+}
+
+// pathFunction identifies a function by its path or file and its name, for
+// baselines read from the text format, which does not record receivers.
+type pathFunction struct {
+	path string
+	name string
+}
+
+func formatMarkdown(coverage cover.Result, baseline *cover.Result) string {
+	var baselineCoverage map[cover.Function]float64
+	// Functions in a text baseline are matched by path, which includes the
+	// line, and then by file and name when only one function has them.
+	var baselineByPath map[pathFunction]float64
+	var baselineByFile map[pathFunction][]float64
+	if baseline != nil {
+		baselineCoverage = make(map[cover.Function]float64, len(baseline.Coverage))
+		baselineByPath = map[pathFunction]float64{}
+		baselineByFile = map[pathFunction][]float64{}
+		for _, funcCoverage := range baseline.Coverage {
+			if funcCoverage.Package != "" {
+				baselineCoverage[coverageFunction(funcCoverage)] = funcCoverage.Coverage
+				continue
+			}
+
+			baselineByPath[pathFunction{path: funcCoverage.Path, name: funcCoverage.Name}] = funcCoverage.Coverage
+			key := coverageFile(funcCoverage)
+			baselineByFile[key] = append(baselineByFile[key], funcCoverage.Coverage)
+		}
+	}
+
+	delta := func(funcCoverage cover.Coverage) string {
+		previous, ok := baselineCoverage[coverageFunction(funcCoverage)]
+		if !ok {
+			previous, ok = baselineByPath[pathFunction{path: funcCoverage.Path, name: funcCoverage.Name}]
+		}
+		if matches := baselineByFile[coverageFile(funcCoverage)]; !ok && len(matches) == 1 {
+			previous, ok = matches[0], true
+		}
+		if !ok {
+			return "new"
+		}
+		return formatDelta(funcCoverage.Coverage - previous)
+	}
+
+	var str strings.Builder
+	str.WriteString("## Deepcover\n\n")
+	str.WriteString(fmt.Sprintf("**Total: %.2f%%**", coverage.ApproxTotalCoverage))
+	if baseline != nil {
+		str.WriteString(fmt.Sprintf(" (%s)", formatDelta(coverage.ApproxTotalCoverage-baseline.ApproxTotalCoverage)))
+	}
+	str.WriteString("\n\n")
+
+	worst := worstCovered(coverage.Coverage)
+	if len(worst) > 0 {
+		str.WriteString("### Worst covered\n\n")
+		str.WriteString(markdownTableHeader([]string{"Function", "Package", "Coverage"}, baseline != nil))
+		for _, funcCoverage := range worst {
+			row := []string{escapeMarkdown(functionName(funcCoverage.Function())), "`" + coverageFunction(funcCoverage).Package + "`", fmt.Sprintf("%.1f%%", funcCoverage.Coverage)}
+			if baseline != nil {
+				row = append(row, delta(funcCoverage))
+			}
+			str.WriteString(markdownTableRow(row))
+		}
+		str.WriteString("\n")
+	}
+
+	byPackage := map[string][]cover.Coverage{}
+	for _, funcCoverage := range coverage.Coverage {
+		byPackage[funcCoverage.Package] = append(byPackage[funcCoverage.Package], funcCoverage)
+	}
+
+	for _, pkg := range cover.Packages(coverage.Coverage) {
+		str.WriteString("<details>\n")
+		str.WriteString(fmt.Sprintf("<summary><code>%s</code>: %.1f%% (%s)</summary>\n\n", pkg.Package, pkg.Coverage, functionCount(pkg.Functions)))
+		str.WriteString(markdownTableHeader([]string{"Function", "Path", "Statements", "Coverage"}, baseline != nil))
+		for _, funcCoverage := range byPackage[pkg.Package] {
+			row := []string{
+				escapeMarkdown(functionName(funcCoverage.Function())),
+				"`" + funcCoverage.Path + "`",
+				fmt.Sprintf("%d", funcCoverage.Statements),
+				fmt.Sprintf("%.1f%%", funcCoverage.Coverage),
+			}
+			if baseline != nil {
+				row = append(row, delta(funcCoverage))
+			}
+			str.WriteString(markdownTableRow(row))
+		}
+		str.WriteString("\n</details>\n\n")
+	}
+
+	return str.String()
+}
+
+// coverageFunction identifies funcCoverage, deriving its package from the
+// path when it is not set, as is the case for coverage read by ReadFile.
+func coverageFunction(funcCoverage cover.Coverage) cover.Function {
+	pkg := funcCoverage.Package
+	if pkg == "" {
+		fileName, _, _ := strings.Cut(funcCoverage.Path, ":")
+		pkg = path.Dir(fileName)
+	}
+
+	return cover.Function{Package: pkg, Receiver: funcCoverage.Receiver, Name: funcCoverage.Name}
+}
+
+// coverageFile identifies funcCoverage by the file in its path and its name.
+func coverageFile(funcCoverage cover.Coverage) pathFunction {
+	fileName, _, _ := strings.Cut(funcCoverage.Path, ":")
+	return pathFunction{path: fileName, name: funcCoverage.Name}
+}
+
+func worstCovered(coverage []cover.Coverage) []cover.Coverage {
+	worst := []cover.Coverage{}
+	for _, funcCoverage := range coverage {
+		if funcCoverage.Coverage < 100 {
+			worst = append(worst, funcCoverage)
+		}
+	}
+
+	sort.SliceStable(worst, func(i, j int) bool {
+		return worst[i].Coverage < worst[j].Coverage
+	})
+	if len(worst) > worstCoveredFunctions {
+		worst = worst[:worstCoveredFunctions]
+	}
+
+	return worst
+}
+
+// formatDelta formats delta rounded to the precision shown, so that rounding
+// noise is not shown as "-0.0%".
+func formatDelta(delta float64) string {
+	delta = math.Round(delta*10) / 10
+	if delta == 0 {
+		delta = 0
+	}
+	return fmt.Sprintf("%+.1f%%", delta)
+}
+
+func markdownTableHeader(columns []string, withDelta bool) string {
+	if withDelta {
+		columns = append(columns, "Δ")
+	}
+
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	return markdownTableRow(columns) + markdownTableRow(separators)
+}
+
+func markdownTableRow(cells []string) string {
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
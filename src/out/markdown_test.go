@@ -0,0 +1,147 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var markdownTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{Path: "example.com/mod/pkg/file1.go:3:", Name: "Function1", Statements: 4, Coverage: 100, Package: "example.com/mod/pkg"},
+		{Path: "example.com/mod/pkg/file1.go:10:", Name: "Function2", Statements: 2, Coverage: 50, Package: "example.com/mod/pkg"},
+		{Path: "example.com/mod/sub/file2.go:5:", Name: "Function3", Statements: 2, Coverage: 0, Package: "example.com/mod/sub"},
+	},
+	ApproxTotalCoverage: 62.5,
+}
+
+func TestOutputMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.md")
+	require.NoError(t, OutputMarkdown(path, markdownTestCoverage, nil))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, formatMarkdown(markdownTestCoverage, nil), string(gotBytes))
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	expected := "## Deepcover\n\n" +
+		"**Total: 62.50%**\n\n" +
+		"### Worst covered\n\n" +
+		"| Function | Package | Coverage |\n" +
+		"| --- | --- | --- |\n" +
+		"| Function3 | `example.com/mod/sub` | 0.0% |\n" +
+		"| Function2 | `example.com/mod/pkg` | 50.0% |\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary><code>example.com/mod/pkg</code>: 83.3% (2 functions)</summary>\n\n" +
+		"| Function | Path | Statements | Coverage |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| Function1 | `example.com/mod/pkg/file1.go:3:` | 4 | 100.0% |\n" +
+		"| Function2 | `example.com/mod/pkg/file1.go:10:` | 2 | 50.0% |\n" +
+		"\n</details>\n\n" +
+		"<details>\n" +
+		"<summary><code>example.com/mod/sub</code>: 0.0% (1 function)</summary>\n\n" +
+		"| Function | Path | Statements | Coverage |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| Function3 | `example.com/mod/sub/file2.go:5:` | 2 | 0.0% |\n" +
+		"\n</details>\n\n"
+
+	assert.Equal(t, expected, formatMarkdown(markdownTestCoverage, nil))
+}
+
+func TestFormatMarkdownBaseline(t *testing.T) {
+	baseline := cover.Result{
+		Coverage: []cover.Coverage{
+			{Path: "example.com/mod/pkg/file1.go:3:", Name: "Function1", Coverage: 80},
+			{Path: "example.com/mod/pkg/file1.go:9:", Name: "Function2", Coverage: 50},
+		},
+		ApproxTotalCoverage: 65,
+	}
+
+	got := formatMarkdown(markdownTestCoverage, &baseline)
+
+	assert.Contains(t, got, "**Total: 62.50%** (-2.5%)\n")
+	assert.Contains(t, got, "| Function | Package | Coverage | Δ |\n")
+	assert.Contains(t, got, "| Function3 | `example.com/mod/sub` | 0.0% | new |\n")
+	assert.Contains(t, got, "| Function1 | `example.com/mod/pkg/file1.go:3:` | 4 | 100.0% | +20.0% |\n")
+	assert.Contains(t, got, "| Function2 | `example.com/mod/pkg/file1.go:10:` | 2 | 50.0% | +0.0% |\n")
+}
+
+func TestFormatMarkdownBaselineMethods(t *testing.T) {
+	coverage := cover.Result{
+		Coverage: []cover.Coverage{
+			{Path: "example.com/mod/pkg/file1.go:3:", Name: "String", Receiver: "A", Statements: 3, Coverage: 100, Package: "example.com/mod/pkg"},
+			{Path: "example.com/mod/pkg/file1.go:7:", Name: "String", Receiver: "*B", Statements: 3, Coverage: 66.67, Package: "example.com/mod/pkg"},
+			{Path: "example.com/mod/pkg/file2.go:4:", Name: "Moved", Statements: 1, Coverage: 100, Package: "example.com/mod/pkg"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		baseline cover.Result
+	}{
+		{
+			name: "text baseline",
+			baseline: cover.Result{
+				Coverage: []cover.Coverage{
+					{Path: "example.com/mod/pkg/file1.go:3:", Name: "String", Coverage: 50},
+					{Path: "example.com/mod/pkg/file1.go:7:", Name: "String", Coverage: 66.66666666666667},
+					{Path: "example.com/mod/pkg/file2.go:2:", Name: "Moved", Coverage: 0},
+				},
+			},
+		},
+		{
+			name: "json baseline",
+			baseline: cover.Result{
+				Coverage: []cover.Coverage{
+					{Path: "example.com/mod/pkg/file1.go:3:", Name: "String", Receiver: "A", Coverage: 50, Package: "example.com/mod/pkg"},
+					{Path: "example.com/mod/pkg/file1.go:5:", Name: "String", Receiver: "*B", Coverage: 66.66666666666667, Package: "example.com/mod/pkg"},
+					{Path: "example.com/mod/pkg/file2.go:2:", Name: "Moved", Coverage: 0, Package: "example.com/mod/pkg"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatMarkdown(coverage, &tt.baseline)
+
+			assert.NotContains(t, got, "new")
+			assert.Contains(t, got, "| A.String | `example.com/mod/pkg/file1.go:3:` | 3 | 100.0% | +50.0% |\n")
+			assert.Contains(t, got, "| (\\*B).String | `example.com/mod/pkg/file1.go:7:` | 3 | 66.7% | +0.0% |\n")
+			assert.Contains(t, got, "| Moved | `example.com/mod/pkg/file2.go:4:` | 1 | 100.0% | +100.0% |\n")
+		})
+	}
+}
+
+func TestFormatDelta(t *testing.T) {
+	assert.Equal(t, "+20.0%", formatDelta(20))
+	assert.Equal(t, "-2.5%", formatDelta(-2.5))
+	assert.Equal(t, "+0.0%", formatDelta(66.67-66.66666666666667))
+	assert.Equal(t, "+0.0%", formatDelta(-0.04))
+}
+
+func TestWorstCovered(t *testing.T) {
+	coverage := []cover.Coverage{
+		{Name: "F1", Coverage: 100},
+		{Name: "F2", Coverage: 90},
+		{Name: "F3", Coverage: 10},
+		{Name: "F4", Coverage: 50},
+		{Name: "F5", Coverage: 0},
+		{Name: "F6", Coverage: 20},
+		{Name: "F7", Coverage: 70},
+	}
+
+	got := worstCovered(coverage)
+
+	names := []string{}
+	for _, c := range got {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"F5", "F3", "F6", "F4", "F7"}, names)
+}
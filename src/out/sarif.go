@@ -0,0 +1,157 @@
+package out
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifRuleID  = "deepcover/low-coverage"
+	sarifBaseID  = "SRCROOT"
+)
+
+func OutputSARIF(path string, coverage cover.Result, threshold float64) error {
+	report, err := formatSARIF(coverage, threshold)
+	if err != nil {
+		return fmt.Errorf("failed to format sarif report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+func formatSARIF(coverage cover.Result, threshold float64) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "deepcover",
+				InformationURI: "https://github.com/leobishop234/deepcover",
+				Rules: []sarifRule{
+					{
+						ID:               sarifRuleID,
+						ShortDescription: sarifMessage{Text: "Statically reachable function is below the coverage threshold"},
+					},
+				},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	if coverage.ModuleDir != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifBaseID: {URI: "file://" + filepath.ToSlash(coverage.ModuleDir) + "/"},
+		}
+	}
+
+	for _, funcCoverage := range belowThreshold(coverage.Coverage, threshold) {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: relativeFile(coverage.ModuleDir, funcCoverage)},
+		}
+		if coverage.ModuleDir != "" {
+			location.ArtifactLocation.URIBaseID = sarifBaseID
+		}
+		if funcCoverage.StartLine > 0 {
+			location.Region = &sarifRegion{StartLine: funcCoverage.StartLine, EndLine: funcCoverage.EndLine}
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    sarifRuleID,
+			Level:     "warning",
+			Message:   sarifMessage{Text: lowCoverageMessage(funcCoverage, threshold)},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	report, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(report) + "\n", nil
+}
+
+func belowThreshold(coverage []cover.Coverage, threshold float64) []cover.Coverage {
+	below := []cover.Coverage{}
+	for _, funcCoverage := range coverage {
+		if funcCoverage.Coverage < threshold {
+			below = append(below, funcCoverage)
+		}
+	}
+
+	return below
+}
+
+func lowCoverageMessage(funcCoverage cover.Coverage, threshold float64) string {
+	message := fmt.Sprintf("%s is %.1f%% covered, below the %.1f%% threshold", functionName(funcCoverage.Function()), funcCoverage.Coverage, threshold)
+	if len(funcCoverage.Tests) > 0 {
+		message += fmt.Sprintf("; reached statically by %s", strings.Join(testNames(funcCoverage.Tests), ", "))
+	}
+
+	return message
+}
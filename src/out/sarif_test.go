@@ -0,0 +1,142 @@
+package out
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sarifTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{
+			Path:      "example.com/mod/pkg/file1.go:3:",
+			Name:      "Function1",
+			Coverage:  100,
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 3,
+			EndLine:   8,
+			Tests:     []cover.Function{{Package: "example.com/mod/pkg", Name: "TestFunction1"}},
+		},
+		{
+			Path:      "example.com/mod/pkg/file1.go:10:",
+			Name:      "Function2",
+			Coverage:  50,
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 10,
+			EndLine:   14,
+			Tests:     []cover.Function{{Package: "example.com/mod/pkg", Name: "TestFunction1"}, {Package: "example.com/mod/pkg", Name: "TestFunction2"}},
+		},
+		{
+			Path:     "example.com/mod/sub/file2.go:5:",
+			Name:     "Function3",
+			Coverage: 0,
+			Package:  "example.com/mod/sub",
+		},
+	},
+	ModuleDir: "/src/mod",
+}
+
+func TestOutputSARIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deepcover.sarif")
+	require.NoError(t, OutputSARIF(path, sarifTestCoverage, 80))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var got sarifLog
+	require.NoError(t, json.Unmarshal(gotBytes, &got))
+	assert.Equal(t, sarifVersion, got.Version)
+}
+
+func TestFormatSARIF(t *testing.T) {
+	tests := []struct {
+		name            string
+		threshold       float64
+		expectedResults []sarifResult
+	}{
+		{
+			name:            "zero threshold",
+			threshold:       0,
+			expectedResults: []sarifResult{},
+		},
+		{
+			name:      "functions below threshold",
+			threshold: 80,
+			expectedResults: []sarifResult{
+				{
+					RuleID:  sarifRuleID,
+					Level:   "warning",
+					Message: sarifMessage{Text: "Function2 is 50.0% covered, below the 80.0% threshold; reached statically by TestFunction1, TestFunction2"},
+					Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "pkg/file1.go", URIBaseID: sarifBaseID},
+						Region:           &sarifRegion{StartLine: 10, EndLine: 14},
+					}}},
+				},
+				{
+					RuleID:  sarifRuleID,
+					Level:   "warning",
+					Message: sarifMessage{Text: "Function3 is 0.0% covered, below the 80.0% threshold"},
+					Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: "example.com/mod/sub/file2.go", URIBaseID: sarifBaseID},
+					}}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := formatSARIF(sarifTestCoverage, tt.threshold)
+			require.NoError(t, err)
+
+			var got sarifLog
+			require.NoError(t, json.Unmarshal([]byte(report), &got))
+
+			assert.Equal(t, sarifSchema, got.Schema)
+			require.Len(t, got.Runs, 1)
+			assert.Equal(t, "deepcover", got.Runs[0].Tool.Driver.Name)
+			assert.Equal(t, "file:///src/mod/", got.Runs[0].OriginalURIBaseIDs[sarifBaseID].URI)
+			assert.Equal(t, tt.expectedResults, got.Runs[0].Results)
+		})
+	}
+}
+
+func TestLowCoverageMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		funcCoverage cover.Coverage
+		expected     string
+	}{
+		{
+			name:         "function",
+			funcCoverage: cover.Coverage{Name: "Function1", Coverage: 50},
+			expected:     "Function1 is 50.0% covered, below the 80.0% threshold",
+		},
+		{
+			name:         "value receiver",
+			funcCoverage: cover.Coverage{Name: "String", Receiver: "T", Coverage: 50},
+			expected:     "T.String is 50.0% covered, below the 80.0% threshold",
+		},
+		{
+			name: "pointer receiver",
+			funcCoverage: cover.Coverage{
+				Name: "String", Receiver: "*U", Coverage: 0,
+				Tests: []cover.Function{{Package: "example.com/mod/pkg", Name: "TestU"}},
+			},
+			expected: "(*U).String is 0.0% covered, below the 80.0% threshold; reached statically by TestU",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lowCoverageMessage(tt.funcCoverage, 80))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package out
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sync"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// AnalyzeFunc runs the analysis, to refresh the data served after code changes.
+type AnalyzeFunc func() (cover.Result, error)
+
+// Serve serves an interactive report of coverage on addr until the server
+// fails. analyze is called when the report is re-run.
+func Serve(addr string, coverage cover.Result, analyze AnalyzeFunc) error {
+	return http.ListenAndServe(addr, newServer(coverage, analyze))
+}
+
+type server struct {
+	mu       sync.RWMutex
+	coverage cover.Result
+	analyze  AnalyzeFunc
+	// running guards against starting a re-run while one is in progress.
+	running sync.Mutex
+
+	mux *http.ServeMux
+}
+
+func newServer(coverage cover.Result, analyze AnalyzeFunc) *server {
+	s := &server{coverage: coverage, analyze: analyze, mux: http.NewServeMux()}
+
+	static, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+
+	s.mux.Handle("GET /", http.FileServer(http.FS(static)))
+	s.mux.HandleFunc("GET /api/result", s.handleResult)
+	s.mux.HandleFunc("GET /api/packages", s.handlePackages)
+	s.mux.HandleFunc("GET /api/tree", s.handleTree)
+	s.mux.HandleFunc("GET /api/source", s.handleSource)
+	s.mux.HandleFunc("POST /api/rerun", s.handleRerun)
+
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *server) result() cover.Result {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.coverage
+}
+
+func (s *server) handleResult(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.result())
+}
+
+func (s *server) handlePackages(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, cover.Packages(s.result().Coverage))
+}
+
+// handleTree serves the call tree of the test given by the package and name
+// query parameters.
+func (s *server) handleTree(w http.ResponseWriter, r *http.Request) {
+	coverage := s.result()
+	fn := queryFunction(r)
+
+	for _, test := range coverage.Tests {
+		if test.Function == fn {
+			writeJSON(w, buildCallTree(test, coverage.Coverage))
+			return
+		}
+	}
+
+	http.Error(w, fmt.Sprintf("unknown test %s.%s", fn.Package, functionName(fn)), http.StatusNotFound)
+}
+
+// handleSource serves the annotated source of the function given by the
+// package, receiver and name query parameters.
+func (s *server) handleSource(w http.ResponseWriter, r *http.Request) {
+	coverage := s.result()
+	fn := queryFunction(r)
+
+	funcCoverage, ok := coverageByFunction(coverage.Coverage)[fn]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown function %s.%s", fn.Package, functionName(fn)), http.StatusNotFound)
+		return
+	}
+
+	source, err := annotateSource(*funcCoverage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, source)
+}
+
+func (s *server) handleRerun(w http.ResponseWriter, r *http.Request) {
+	if s.analyze == nil {
+		http.Error(w, "re-running is not available", http.StatusNotImplemented)
+		return
+	}
+	if !s.running.TryLock() {
+		http.Error(w, "a re-run is already in progress", http.StatusConflict)
+		return
+	}
+	defer s.running.Unlock()
+
+	coverage, err := s.analyze()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to re-run: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.coverage = coverage
+	s.mu.Unlock()
+
+	writeJSON(w, coverage)
+}
+
+func queryFunction(r *http.Request) cover.Function {
+	return cover.Function{
+		Package:  r.URL.Query().Get("package"),
+		Receiver: r.URL.Query().Get("receiver"),
+		Name:     r.URL.Query().Get("name"),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,152 @@
+package out
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	s := newServer(htmlTestCoverage(t), nil)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "index",
+			method:         http.MethodGet,
+			url:            "/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `<script src="app.js"></script>`,
+		},
+		{
+			name:           "script",
+			method:         http.MethodGet,
+			url:            "/app.js",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `fetchJSON("api/result")`,
+		},
+		{
+			name:           "result",
+			method:         http.MethodGet,
+			url:            "/api/result",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"ApproxTotalCoverage":66.7`,
+		},
+		{
+			name:           "packages",
+			method:         http.MethodGet,
+			url:            "/api/packages",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"Package":"example/path","Functions":2,"Statements":3,`,
+		},
+		{
+			name:           "tree",
+			method:         http.MethodGet,
+			url:            "/api/tree?package=example/path&name=TestFunction1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"Children":[{"Function":{"Package":"example/path","Receiver":"","Name":"Function1"}`,
+		},
+		{
+			name:           "unknown test",
+			method:         http.MethodGet,
+			url:            "/api/tree?package=example/path&name=TestMissing",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "unknown test example/path.TestMissing",
+		},
+		{
+			name:           "source",
+			method:         http.MethodGet,
+			url:            "/api/source?package=example/path&name=Function1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"Number":7,"Text":"\treturn -x","Class":"uncovered"}`,
+		},
+		{
+			name:           "source of unknown method",
+			method:         http.MethodGet,
+			url:            "/api/source?package=example/path&receiver=*T&name=Function1",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "unknown function example/path.(*T).Function1",
+		},
+		{
+			name:           "source without location",
+			method:         http.MethodGet,
+			url:            "/api/source?package=example/path&name=Function2",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "source location unknown",
+		},
+		{
+			name:           "rerun without analysis",
+			method:         http.MethodPost,
+			url:            "/api/rerun",
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestServerRerun(t *testing.T) {
+	tests := []struct {
+		name            string
+		analyze         AnalyzeFunc
+		expectedStatus  int
+		expectedTotal   float64
+		expectedMessage string
+	}{
+		{
+			name: "refreshes the result",
+			analyze: func() (cover.Result, error) {
+				return cover.Result{ApproxTotalCoverage: 100}, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  100,
+		},
+		{
+			name: "keeps the result on failure",
+			analyze: func() (cover.Result, error) {
+				return cover.Result{}, fmt.Errorf("tests failed")
+			},
+			expectedStatus:  http.StatusInternalServerError,
+			expectedTotal:   66.7,
+			expectedMessage: "failed to re-run: tests failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(htmlTestCoverage(t), tt.analyze)
+
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/rerun", nil))
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedMessage)
+
+			recorder = httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/result", nil))
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var result cover.Result
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+			assert.Equal(t, tt.expectedTotal, result.ApproxTotalCoverage)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package out
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+func OutputSonar(path string, coverage cover.Result) error {
+	report, err := formatSonar(coverage)
+	if err != nil {
+		return fmt.Errorf("failed to format sonar report: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+type sonarCoverage struct {
+	XMLName xml.Name    `xml:"coverage"`
+	Version int         `xml:"version,attr"`
+	Files   []sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path  string      `xml:"path,attr"`
+	Lines []sonarLine `xml:"lineToCover"`
+}
+
+type sonarLine struct {
+	LineNumber int  `xml:"lineNumber,attr"`
+	Covered    bool `xml:"covered,attr"`
+}
+
+func formatSonar(coverage cover.Result) (string, error) {
+	files := map[string]map[int]bool{}
+	for _, funcCoverage := range coverage.Coverage {
+		path := relativeFile(coverage.ModuleDir, funcCoverage)
+		if _, ok := files[path]; !ok {
+			files[path] = map[int]bool{}
+		}
+
+		for _, line := range coverageLines(funcCoverage) {
+			files[path][line.Line] = files[path][line.Line] || line.Count > 0
+		}
+	}
+
+	report := sonarCoverage{Version: 1}
+	for path, lines := range files {
+		file := sonarFile{Path: path}
+		for number, covered := range lines {
+			file.Lines = append(file.Lines, sonarLine{LineNumber: number, Covered: covered})
+		}
+		sort.Slice(file.Lines, func(i, j int) bool {
+			return file.Lines[i].LineNumber < file.Lines[j].LineNumber
+		})
+
+		report.Files = append(report.Files, file)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	var str strings.Builder
+	encoder := xml.NewEncoder(&str)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(report); err != nil {
+		return "", err
+	}
+	str.WriteString("\n")
+
+	return str.String(), nil
+}
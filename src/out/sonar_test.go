@@ -0,0 +1,70 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sonarTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{
+			Path:      "example.com/mod/pkg/file1.go:3:",
+			Name:      "Function1",
+			Package:   "example.com/mod/pkg",
+			File:      "/src/mod/pkg/file1.go",
+			StartLine: 3,
+			EndLine:   8,
+			Blocks: []cover.Block{
+				{StartLine: 3, EndLine: 4, NumStmt: 2, Count: 1},
+				{StartLine: 7, EndLine: 7, NumStmt: 1, Count: 0},
+			},
+		},
+		{
+			Path:      "example.com/mod/sub/file2.go:5:",
+			Name:      "Function2",
+			Package:   "example.com/mod/sub",
+			File:      "/src/mod/sub/file2.go",
+			StartLine: 5,
+			EndLine:   6,
+			Blocks: []cover.Block{
+				{StartLine: 5, EndLine: 5, NumStmt: 1, Count: 0},
+			},
+		},
+	},
+	ModuleDir: "/src/mod",
+}
+
+func TestOutputSonar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sonar.xml")
+	require.NoError(t, OutputSonar(path, sonarTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	expected, err := formatSonar(sonarTestCoverage)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(gotBytes))
+}
+
+func TestFormatSonar(t *testing.T) {
+	expected := `<coverage version="1">
+	<file path="pkg/file1.go">
+		<lineToCover lineNumber="3" covered="true"></lineToCover>
+		<lineToCover lineNumber="4" covered="true"></lineToCover>
+		<lineToCover lineNumber="7" covered="false"></lineToCover>
+	</file>
+	<file path="sub/file2.go">
+		<lineToCover lineNumber="5" covered="false"></lineToCover>
+	</file>
+</coverage>
+`
+
+	got, err := formatSonar(sonarTestCoverage)
+	require.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
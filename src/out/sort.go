@@ -0,0 +1,101 @@
+package out
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+// sortCoverage returns a copy of coverage sorted by coverage, path, name or
+// statements. Statements sort largest first, every other key ascending.
+func sortCoverage(coverage []cover.Coverage, by string) ([]cover.Coverage, error) {
+	var less func(a, b cover.Coverage) bool
+	switch by {
+	case "coverage":
+		less = func(a, b cover.Coverage) bool { return a.Coverage < b.Coverage }
+	case "path":
+		less = pathLess
+	case "name":
+		less = func(a, b cover.Coverage) bool { return a.Name < b.Name }
+	case "statements":
+		less = func(a, b cover.Coverage) bool { return a.Statements > b.Statements }
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", by)
+	}
+
+	sorted := make([]cover.Coverage, len(coverage))
+	copy(sorted, coverage)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if less(sorted[i], sorted[j]) {
+			return true
+		}
+		if less(sorted[j], sorted[i]) {
+			return false
+		}
+		return pathLess(sorted[i], sorted[j])
+	})
+
+	return sorted, nil
+}
+
+func pathLess(a, b cover.Coverage) bool {
+	aFile, aLine := splitPath(a.Path)
+	bFile, bLine := splitPath(b.Path)
+	if aFile != bFile {
+		return aFile < bFile
+	}
+	return aLine < bLine
+}
+
+// splitPath splits a "file.go:line:" path as printed by go tool cover.
+func splitPath(path string) (string, int) {
+	fileName, rest, _ := strings.Cut(path, ":")
+	line, _ := strconv.Atoi(strings.TrimSuffix(rest, ":"))
+	return fileName, line
+}
+
+type coverageGroup struct {
+	Key        string
+	Coverage   []cover.Coverage
+	Statements int
+	Total      float64
+}
+
+// groupCoverage groups coverage by package or file, in order of first appearance.
+func groupCoverage(coverage []cover.Coverage, by string) ([]coverageGroup, error) {
+	var key func(c cover.Coverage) string
+	switch by {
+	case "package":
+		key = func(c cover.Coverage) string { return coverageFunction(c).Package }
+	case "file":
+		key = func(c cover.Coverage) string {
+			fileName, _ := splitPath(c.Path)
+			return fileName
+		}
+	default:
+		return nil, fmt.Errorf("unknown group key %q", by)
+	}
+
+	groups := []coverageGroup{}
+	index := map[string]int{}
+	for _, funcCoverage := range coverage {
+		k := key(funcCoverage)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, coverageGroup{Key: k})
+		}
+		groups[i].Coverage = append(groups[i].Coverage, funcCoverage)
+		groups[i].Statements += funcCoverage.Statements
+	}
+
+	for i := range groups {
+		groups[i].Total = cover.TotalCoverage(groups[i].Coverage)
+	}
+
+	return groups, nil
+}
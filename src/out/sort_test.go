@@ -0,0 +1,110 @@
+package out
+
+import (
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sortTestCoverage = []cover.Coverage{
+	{Path: "example.com/mod/pkg/b.go:10:", Name: "Beta", Package: "example.com/mod/pkg", Statements: 2, Coverage: 50},
+	{Path: "example.com/mod/pkg/a.go:9:", Name: "Gamma", Package: "example.com/mod/pkg", Statements: 8, Coverage: 100},
+	{Path: "example.com/mod/pkg/a.go:20:", Name: "Alpha", Package: "example.com/mod/pkg", Statements: 4, Coverage: 50},
+	{Path: "example.com/mod/sub/c.go:3:", Name: "Delta", Package: "example.com/mod/sub", Statements: 4, Coverage: 0},
+}
+
+func coverageNames(coverage []cover.Coverage) []string {
+	names := []string{}
+	for _, c := range coverage {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestSortCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		by          string
+		expected    []string
+		expectError bool
+	}{
+		{name: "by coverage", by: "coverage", expected: []string{"Delta", "Alpha", "Beta", "Gamma"}},
+		{name: "by path", by: "path", expected: []string{"Gamma", "Alpha", "Beta", "Delta"}},
+		{name: "by name", by: "name", expected: []string{"Alpha", "Beta", "Delta", "Gamma"}},
+		{name: "by statements", by: "statements", expected: []string{"Gamma", "Alpha", "Delta", "Beta"}},
+		{name: "unknown key", by: "unknown", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortCoverage(sortTestCoverage, tt.by)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, coverageNames(got))
+			assert.Equal(t, "Beta", sortTestCoverage[0].Name, "input should not be modified")
+		})
+	}
+}
+
+func TestGroupCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		by          string
+		expected    []coverageGroup
+		expectError bool
+	}{
+		{
+			name: "by package",
+			by:   "package",
+			expected: []coverageGroup{
+				{Key: "example.com/mod/pkg", Coverage: sortTestCoverage[:3], Statements: 14, Total: 78.57142857142857},
+				{Key: "example.com/mod/sub", Coverage: sortTestCoverage[3:], Statements: 4, Total: 0},
+			},
+		},
+		{
+			name: "by file",
+			by:   "file",
+			expected: []coverageGroup{
+				{Key: "example.com/mod/pkg/b.go", Coverage: sortTestCoverage[:1], Statements: 2, Total: 50},
+				{Key: "example.com/mod/pkg/a.go", Coverage: sortTestCoverage[1:3], Statements: 12, Total: 83.33333333333334},
+				{Key: "example.com/mod/sub/c.go", Coverage: sortTestCoverage[3:], Statements: 4, Total: 0},
+			},
+		},
+		{name: "unknown key", by: "unknown", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := groupCoverage(sortTestCoverage, tt.by)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.expected))
+			for i := range tt.expected {
+				assert.Equal(t, tt.expected[i].Key, got[i].Key)
+				assert.Equal(t, tt.expected[i].Coverage, got[i].Coverage)
+				assert.Equal(t, tt.expected[i].Statements, got[i].Statements)
+				assert.InDelta(t, tt.expected[i].Total, got[i].Total, 0.0001)
+			}
+		})
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	fileName, line := splitPath("example.com/mod/pkg/a.go:20:")
+	assert.Equal(t, "example.com/mod/pkg/a.go", fileName)
+	assert.Equal(t, 20, line)
+
+	fileName, line = splitPath("example.com/mod/pkg/a.go")
+	assert.Equal(t, "example.com/mod/pkg/a.go", fileName)
+	assert.Equal(t, 0, line)
+}
@@ -0,0 +1,74 @@
+package out
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+// templateData is the value templates are executed with.
+type templateData struct {
+	cover.Result
+	Packages []cover.PackageCoverage
+}
+
+// OutputTemplate renders coverage with the text/template at templatePath.
+func OutputTemplate(path, templatePath string, coverage cover.Result) error {
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %v", err)
+	}
+
+	report, err := formatTemplate(filepath.Base(templatePath), string(content), coverage)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %v", err)
+	}
+
+	return writeOutput(path, report)
+}
+
+func formatTemplate(name, text string, coverage cover.Result) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(coverage)).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var str strings.Builder
+	if err := tmpl.Execute(&str, templateData{
+		Result:   coverage,
+		Packages: cover.Packages(coverage.Coverage),
+	}); err != nil {
+		return "", err
+	}
+
+	return str.String(), nil
+}
+
+func templateFuncs(coverage cover.Result) template.FuncMap {
+	return template.FuncMap{
+		"pct": func(coverage float64) string {
+			return fmt.Sprintf("%.1f%%", coverage)
+		},
+		"sortBy": func(by string, coverage []cover.Coverage) ([]cover.Coverage, error) {
+			return sortCoverage(coverage, by)
+		},
+		"groupBy": func(by string, coverage []cover.Coverage) ([]coverageGroup, error) {
+			return groupCoverage(coverage, by)
+		},
+		"below": func(threshold float64, coverage []cover.Coverage) []cover.Coverage {
+			return belowThreshold(coverage, threshold)
+		},
+		"relFile": func(funcCoverage cover.Coverage) string {
+			return relativeFile(coverage.ModuleDir, funcCoverage)
+		},
+		"callTree": func(test cover.Test) *callNode {
+			return buildCallTree(test, coverage.Coverage)
+		},
+		"names": testNames,
+		"join":  strings.Join,
+	}
+}
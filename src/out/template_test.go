@@ -0,0 +1,105 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var templateTestCoverage = cover.Result{
+	Coverage: []cover.Coverage{
+		{Path: "example.com/mod/pkg/file1.go:3:", Name: "Function1", Statements: 4, Coverage: 100, Package: "example.com/mod/pkg", File: "/src/mod/pkg/file1.go", Tests: []cover.Function{{Package: "example.com/mod/pkg", Name: "TestA"}, {Package: "example.com/mod/pkg", Name: "TestB"}}},
+		{Path: "example.com/mod/pkg/file1.go:10:", Name: "Function2", Statements: 2, Coverage: 50, Package: "example.com/mod/pkg", File: "/src/mod/pkg/file1.go"},
+		{Path: "example.com/mod/sub/file2.go:5:", Name: "Function3", Statements: 2, Coverage: 0, Package: "example.com/mod/sub", File: "/src/mod/sub/file2.go"},
+	},
+	ApproxTotalCoverage: 62.5,
+	Tests: []cover.Test{
+		{
+			Function: cover.Function{Package: "example.com/mod/pkg", Name: "TestA"},
+			Calls: []cover.Call{
+				{Caller: cover.Function{Package: "example.com/mod/pkg", Name: "TestA"}, Callee: cover.Function{Package: "example.com/mod/pkg", Name: "Function1"}},
+			},
+		},
+	},
+	ModuleDir: "/src/mod",
+}
+
+func TestOutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "report.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte("Total: {{pct .ApproxTotalCoverage}}\n"), 0o644))
+
+	path := filepath.Join(dir, "report.txt")
+	require.NoError(t, OutputTemplate(path, templatePath, templateTestCoverage))
+
+	gotBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Total: 62.5%\n", string(gotBytes))
+}
+
+func TestOutputTemplateMissing(t *testing.T) {
+	err := OutputTemplate("", filepath.Join(t.TempDir(), "missing.tmpl"), templateTestCoverage)
+	assert.Error(t, err)
+}
+
+func TestFormatTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "sorted functions",
+			text:     `{{range sortBy "coverage" .Coverage}}{{.Name}} {{pct .Coverage}}{{"\n"}}{{end}}`,
+			expected: "Function3 0.0%\nFunction2 50.0%\nFunction1 100.0%\n",
+		},
+		{
+			name:     "grouped functions",
+			text:     `{{range groupBy "package" .Coverage}}{{.Key}} {{pct .Total}} {{len .Coverage}}{{"\n"}}{{end}}`,
+			expected: "example.com/mod/pkg 83.3% 2\nexample.com/mod/sub 0.0% 1\n",
+		},
+		{
+			name:     "package summaries",
+			text:     `{{range .Packages}}{{.Package}} {{.Functions}}{{"\n"}}{{end}}`,
+			expected: "example.com/mod/pkg 2\nexample.com/mod/sub 1\n",
+		},
+		{
+			name:     "functions below threshold with files and tests",
+			text:     `{{range below 60.0 .Coverage}}{{relFile .}} {{.Name}}{{"\n"}}{{end}}{{join (names (index .Coverage 0).Tests) ","}}`,
+			expected: "pkg/file1.go Function2\nsub/file2.go Function3\nTestA,TestB",
+		},
+		{
+			name:     "call tree",
+			text:     `{{range .Tests}}{{$tree := callTree .}}{{$tree.Function.Name}}:{{range $tree.Children}} {{.Function.Name}} {{pct .Coverage.Coverage}}{{end}}{{end}}`,
+			expected: "TestA: Function1 100.0%",
+		},
+		{
+			name:        "invalid sort key",
+			text:        `{{sortBy "unknown" .Coverage}}`,
+			expectError: true,
+		},
+		{
+			name:        "invalid template",
+			text:        `{{range}}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatTemplate("test", tt.text, templateTestCoverage)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
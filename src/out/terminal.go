@@ -2,32 +2,142 @@ package out
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/leobishop234/deepcover/src/cover"
+	"golang.org/x/term"
 )
 
-func OutputTerminal(coverage cover.Result) {
-	fmt.Print(formatTerminal(coverage))
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGreen  = "\033[32m"
+
+	// Coverage at or above colorHighThreshold is green, at or above
+	// colorLowThreshold yellow and red otherwise.
+	colorHighThreshold = 80
+	colorLowThreshold  = 50
+)
+
+// SortKeys are the keys the terminal table can be sorted by.
+var SortKeys = []string{"coverage", "path", "name", "statements"}
+
+// Views are the ways coverage can be shown in the terminal: a table of
+// functions, or the call tree of each test.
+var Views = []string{"table", "tree"}
+
+type terminalOptions struct {
+	sort  string
+	color bool
+	// width is the terminal width paths are truncated to fit, or 0 to disable truncation.
+	width int
 }
 
-func formatTerminal(coverage cover.Result) string {
+func OutputTerminal(coverage cover.Result, options Options) error {
+	terminalOpts := terminalOptions{sort: options.Sort}
+
+	fd := int(os.Stdout.Fd())
+	if term.IsTerminal(fd) {
+		terminalOpts.color = os.Getenv("NO_COLOR") == ""
+		if width, _, err := term.GetSize(fd); err == nil {
+			terminalOpts.width = width
+		}
+	}
+
+	if options.View == "tree" {
+		fmt.Println(formatTree(coverage, terminalOpts))
+		return nil
+	}
+
+	table, err := formatTerminal(coverage, terminalOpts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(table)
+	return nil
+}
+
+type terminalRow struct {
+	// indent is written before the path, which is truncated on its own.
+	indent   string
+	path     string
+	name     string
+	coverage float64
+}
+
+func formatTerminal(coverage cover.Result, options terminalOptions) (string, error) {
+	if options.sort == "" {
+		options.sort = "path"
+	}
+
+	sorted, err := sortCoverage(coverage.Coverage, options.sort)
+	if err != nil {
+		return "", err
+	}
+
+	groups, err := groupCoverage(sorted, "package")
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+
+	rows := []terminalRow{}
+	for _, group := range groups {
+		rows = append(rows, terminalRow{
+			path:     group.Key,
+			name:     "(" + functionCount(len(group.Coverage)) + ")",
+			coverage: group.Total,
+		})
+		for _, funcCoverage := range group.Coverage {
+			name := functionName(funcCoverage.Function())
+			if len(funcCoverage.TypeArguments) > 0 {
+				name += " " + strings.Join(funcCoverage.TypeArguments, " ")
+			}
+			if funcCoverage.Setup {
+				name += " (shared setup)"
+			}
+
+			rows = append(rows, terminalRow{
+				indent:   "  ",
+				path:     packageRelativePath(funcCoverage),
+				name:     name,
+				coverage: funcCoverage.Coverage,
+			})
+		}
+	}
+
 	var pathLen, nameLen, coverageLen int
-	for _, funcCoverage := range coverage.Coverage {
-		if len(funcCoverage.Path) > pathLen {
-			pathLen = len(funcCoverage.Path)
+	for _, row := range rows {
+		if len(row.indent+row.path) > pathLen {
+			pathLen = len(row.indent + row.path)
 		}
-		if len(funcCoverage.Name) > nameLen {
-			nameLen = len(funcCoverage.Name)
+		if len(row.name) > nameLen {
+			nameLen = len(row.name)
 		}
-		if len(fmt.Sprintf("%.1f%%", funcCoverage.Coverage)) > coverageLen {
-			coverageLen = len(fmt.Sprintf("%.1f%%", funcCoverage.Coverage))
+		if len(fmt.Sprintf("%.1f%%", row.coverage)) > coverageLen {
+			coverageLen = len(fmt.Sprintf("%.1f%%", row.coverage))
 		}
 	}
 	pathLen += 2
 	nameLen += 2
 	coverageLen += 2
 
+	if options.width > 0 {
+		available := options.width - nameLen - coverageLen - 2
+		if available < len("PATH")+2 {
+			available = len("PATH") + 2
+		}
+		if pathLen > available {
+			pathLen = available
+		}
+	}
+
 	var result strings.Builder
 
 	title := fmt.Sprintf("%-*s %-*s %-*s", pathLen, "PATH", nameLen, "FUNCTION", coverageLen, "COVERAGE")
@@ -36,19 +146,176 @@ func formatTerminal(coverage cover.Result) string {
 	result.WriteString(strings.Repeat("-", len(title)))
 	result.WriteString("\n")
 
-	for _, funcCoverage := range coverage.Coverage {
-		coverageStr := fmt.Sprintf("%.1f%%", funcCoverage.Coverage)
-		line := fmt.Sprintf("%-*s %-*s %-*s\n",
+	for _, row := range rows {
+		coverageStr := fmt.Sprintf("%.1f%%", row.coverage)
+		padding := strings.Repeat(" ", coverageLen-len(coverageStr))
+		if options.color {
+			coverageStr = colorize(coverageStr, row.coverage)
+		}
+
+		line := fmt.Sprintf("%-*s %-*s %s%s\n",
 			pathLen,
-			funcCoverage.Path,
+			row.indent+truncatePath(row.path, pathLen-2-len(row.indent)),
 			nameLen,
-			funcCoverage.Name,
-			coverageLen,
-			coverageStr)
+			row.name,
+			coverageStr,
+			padding)
 		result.WriteString(line)
 	}
 
 	result.WriteString(fmt.Sprintf("Total: %.2f%%", coverage.ApproxTotalCoverage))
 
+	if len(coverage.TestHelpers) > 0 {
+		result.WriteString("\n\n")
+		result.WriteString(formatTestHelpers(coverage.TestHelpers, options))
+	}
+
+	if len(coverage.Initializers) > 0 {
+		result.WriteString("\n\n")
+		result.WriteString(formatInitializers(coverage.Initializers, options))
+	}
+
+	return result.String(), nil
+}
+
+// formatTestHelpers lists the test helpers below the table. Their coverage is
+// not measured, so the tests reaching them are shown instead.
+func formatTestHelpers(helpers []cover.Coverage, options terminalOptions) string {
+	sorted := make([]cover.Coverage, len(helpers))
+	copy(sorted, helpers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	pathLen, nameLen := len("TEST HELPER"), len("FUNCTION")
+	for _, helper := range sorted {
+		if len(helper.Path) > pathLen {
+			pathLen = len(helper.Path)
+		}
+		if name := functionName(helper.Function()); len(name) > nameLen {
+			nameLen = len(name)
+		}
+	}
+	pathLen += 2
+	nameLen += 2
+
+	if options.width > 0 {
+		available := options.width - nameLen - len("TESTS") - 2
+		if available < len("TEST HELPER")+2 {
+			available = len("TEST HELPER") + 2
+		}
+		if pathLen > available {
+			pathLen = available
+		}
+	}
+
+	var result strings.Builder
+	title := fmt.Sprintf("%-*s %-*s %s", pathLen, "TEST HELPER", nameLen, "FUNCTION", "TESTS")
+	result.WriteString(title)
+	result.WriteString("\n")
+	result.WriteString(strings.Repeat("-", len(title)))
+	result.WriteString("\n")
+
+	for _, helper := range sorted {
+		result.WriteString(fmt.Sprintf("%-*s %-*s %d\n",
+			pathLen,
+			truncatePath(helper.Path, pathLen-2),
+			nameLen,
+			functionName(helper.Function()),
+			len(helper.Tests)))
+	}
+	result.WriteString(fmt.Sprintf("%d test helpers, not included in the total", len(helpers)))
+
+	return result.String()
+}
+
+// functionCount formats a number of functions, like "1 function".
+func functionCount(n int) string {
+	if n == 1 {
+		return "1 function"
+	}
+	return fmt.Sprintf("%d functions", n)
+}
+
+// packageRelativePath trims the package from the function's path, as the
+// package is already shown by the group's subtotal row.
+func packageRelativePath(funcCoverage cover.Coverage) string {
+	return strings.TrimPrefix(funcCoverage.Path, coverageFunction(funcCoverage).Package+"/")
+}
+
+// truncatePath shortens path to at most width characters, keeping its end.
+func truncatePath(path string, width int) string {
+	const ellipsis = "..."
+	if len(path) <= width || width <= len(ellipsis) {
+		return path
+	}
+
+	return ellipsis + path[len(path)-(width-len(ellipsis)):]
+}
+
+func colorize(s string, coverage float64) string {
+	color := colorRed
+	switch {
+	case coverage >= colorHighThreshold:
+		color = colorGreen
+	case coverage >= colorLowThreshold:
+		color = colorYellow
+	}
+
+	return color + s + colorReset
+}
+
+// formatInitializers lists the functions only reached through package
+// initialization below the table, with their own total.
+func formatInitializers(initializers []cover.Coverage, options terminalOptions) string {
+	sorted := make([]cover.Coverage, len(initializers))
+	copy(sorted, initializers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	pathLen, nameLen := len("INITIALIZER"), len("FUNCTION")
+	for _, initializer := range sorted {
+		if len(initializer.Path) > pathLen {
+			pathLen = len(initializer.Path)
+		}
+		if name := functionName(initializer.Function()); len(name) > nameLen {
+			nameLen = len(name)
+		}
+	}
+	pathLen += 2
+	nameLen += 2
+
+	if options.width > 0 {
+		available := options.width - nameLen - len("COVERAGE") - 2
+		if available < len("INITIALIZER")+2 {
+			available = len("INITIALIZER") + 2
+		}
+		if pathLen > available {
+			pathLen = available
+		}
+	}
+
+	var result strings.Builder
+	title := fmt.Sprintf("%-*s %-*s %s", pathLen, "INITIALIZER", nameLen, "FUNCTION", "COVERAGE")
+	result.WriteString(title)
+	result.WriteString("\n")
+	result.WriteString(strings.Repeat("-", len(title)))
+	result.WriteString("\n")
+
+	for _, initializer := range sorted {
+		coverageStr := fmt.Sprintf("%.1f%%", initializer.Coverage)
+		if options.color {
+			coverageStr = colorize(coverageStr, initializer.Coverage)
+		}
+		result.WriteString(fmt.Sprintf("%-*s %-*s %s\n",
+			pathLen,
+			truncatePath(initializer.Path, pathLen-2),
+			nameLen,
+			functionName(initializer.Function()),
+			coverageStr))
+	}
+	result.WriteString(fmt.Sprintf("Initializers: %.2f%%, not included in the total", cover.TotalCoverage(initializers)))
+
 	return result.String()
 }
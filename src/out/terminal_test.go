@@ -6,50 +6,253 @@ import (
 
 	"github.com/leobishop234/deepcover/src/cover"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var terminalTestCoverage = cover.Result{
 	Coverage: []cover.Coverage{
 		{
-			Path:     "example/path/file1.go",
-			Name:     "package.Function1",
-			Coverage: 100,
+			Path:       "example/path/file1.go:3:",
+			Name:       "Function1",
+			Package:    "example/path",
+			Statements: 2,
+			Coverage:   100,
 		},
 		{
-			Path:     "example/path/file2.go",
-			Name:     "package.Function2",
-			Coverage: 50,
+			Path:       "example/other/file2.go:7:",
+			Name:       "Function2",
+			Package:    "example/other",
+			Statements: 4,
+			Coverage:   50,
 		},
 		{
-			Path:     "example/path/file3.go",
-			Name:     "package.Function3",
-			Coverage: 0,
+			Path:       "example/path/file3.go:1:",
+			Name:       "Function3",
+			Package:    "example/path",
+			Statements: 2,
+			Coverage:   0,
 		},
 	},
 	ApproxTotalCoverage: 50,
 }
 
 func TestOutputTerminal(t *testing.T) {
-	OutputTerminal(terminalTestCoverage)
+	err := OutputTerminal(terminalTestCoverage, Options{})
+	assert.NoError(t, err)
 }
 
 func TestFormatTerminal(t *testing.T) {
-	result := formatTerminal(terminalTestCoverage)
+	result, err := formatTerminal(terminalTestCoverage, terminalOptions{})
+	require.NoError(t, err)
 
 	assert.Contains(t, result, "PATH")
 	assert.Contains(t, result, "FUNCTION")
 	assert.Contains(t, result, "COVERAGE")
 
-	assert.Contains(t, result, "package.Function1")
-	assert.Contains(t, result, "package.Function2")
-	assert.Contains(t, result, "package.Function3")
+	assert.Contains(t, result, "Function1")
+	assert.Contains(t, result, "Function2")
+	assert.Contains(t, result, "Function3")
 
 	assert.Contains(t, result, "100.0%")
 	assert.Contains(t, result, "50.0%")
 	assert.Contains(t, result, "0.0%")
 
 	assert.Contains(t, result, "Total: 50.00%")
+	assert.Contains(t, result, "(1 function) ")
+	assert.Contains(t, result, "(2 functions)")
+	assert.NotContains(t, result, "\033[")
 
 	lines := strings.Split(strings.TrimSpace(result), "\n")
-	assert.Equal(t, 6, len(lines))
+	assert.Equal(t, 8, len(lines))
+}
+
+func TestFormatTerminalOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		sort          string
+		expectedOrder []string
+		expectedErr   bool
+	}{
+		{
+			name:          "default sorts by path",
+			expectedOrder: []string{"example/other", "Function2", "example/path", "Function1", "Function3"},
+		},
+		{
+			name:          "by coverage",
+			sort:          "coverage",
+			expectedOrder: []string{"example/other", "Function2", "example/path", "Function3", "Function1"},
+		},
+		{
+			name:        "unknown key",
+			sort:        "size",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := formatTerminal(terminalTestCoverage, terminalOptions{sort: tt.sort})
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			last := -1
+			for _, s := range tt.expectedOrder {
+				index := strings.Index(result, s)
+				assert.Greater(t, index, last, s)
+				last = index
+			}
+		})
+	}
+}
+
+func TestFormatTerminalSubtotals(t *testing.T) {
+	result, err := formatTerminal(terminalTestCoverage, terminalOptions{})
+	require.NoError(t, err)
+
+	lines := strings.Split(result, "\n")
+	assert.Regexp(t, `^example/other\s+\(1 function\)\s+50\.0%`, lines[2])
+	assert.Regexp(t, `^  file2\.go:7:\s+Function2\s+50\.0%`, lines[3])
+	assert.Regexp(t, `^example/path\s+\(2 functions\)\s+50\.0%`, lines[4])
+}
+
+func TestFormatTerminalTypeArguments(t *testing.T) {
+	coverage := terminalTestCoverage
+	coverage.Coverage = []cover.Coverage{
+		{Path: "example/path/map.go:3:", Name: "Map", Package: "example/path", Coverage: 100, TypeArguments: []string{"[int, string]", "[string, int]"}},
+	}
+
+	result, err := formatTerminal(coverage, terminalOptions{})
+	require.NoError(t, err)
+	assert.Regexp(t, `  map\.go:3:\s+Map \[int, string\] \[string, int\]\s+100\.0%`, result)
+}
+
+func TestFormatTerminalSetup(t *testing.T) {
+	coverage := terminalTestCoverage
+	coverage.Coverage = []cover.Coverage{
+		{Path: "example/path/server.go:8:", Name: "startServer", Package: "example/path", Coverage: 100, Setup: true},
+		{Path: "example/path/server.go:20:", Name: "handle", Package: "example/path", Coverage: 50},
+	}
+
+	result, err := formatTerminal(coverage, terminalOptions{})
+	require.NoError(t, err)
+	assert.Regexp(t, `  server\.go:8:\s+startServer \(shared setup\)\s+100\.0%`, result)
+	assert.Regexp(t, `  server\.go:20:\s+handle\s+50\.0%`, result)
+}
+
+func TestFormatTerminalTestHelpers(t *testing.T) {
+	coverage := terminalTestCoverage
+	coverage.TestHelpers = []cover.Coverage{
+		{Path: "example/path/file3_test.go:20:", Name: "setup", Package: "example/path", Tests: []cover.Function{{Package: "example/path", Name: "TestA"}, {Package: "example/path", Name: "TestB"}}},
+		{Path: "example/path/file1_test.go:8:", Name: "newFixture", Package: "example/path", Tests: []cover.Function{{Package: "example/path", Name: "TestA"}}},
+	}
+
+	result, err := formatTerminal(coverage, terminalOptions{})
+	require.NoError(t, err)
+
+	lines := strings.Split(result, "\n")
+	require.Len(t, lines, 14)
+	assert.Equal(t, "Total: 50.00%", lines[7])
+	assert.Regexp(t, `^TEST HELPER\s+FUNCTION\s+TESTS$`, lines[9])
+	assert.Regexp(t, `^example/path/file1_test\.go:8:\s+newFixture\s+1$`, lines[11])
+	assert.Regexp(t, `^example/path/file3_test\.go:20:\s+setup\s+2$`, lines[12])
+	assert.Equal(t, "2 test helpers, not included in the total", lines[13])
+}
+
+func TestFormatTerminalInitializers(t *testing.T) {
+	coverage := terminalTestCoverage
+	coverage.Initializers = []cover.Coverage{
+		{Path: "example/path/registry.go:9:", Name: "init", Package: "example/path", Statements: 3, Coverage: 100},
+		{Path: "example/path/registry.go:4:", Name: "defaultRegistry", Package: "example/path", Statements: 1, Coverage: 0},
+	}
+
+	result, err := formatTerminal(coverage, terminalOptions{})
+	require.NoError(t, err)
+
+	lines := strings.Split(result, "\n")
+	require.Len(t, lines, 14)
+	assert.Equal(t, "Total: 50.00%", lines[7])
+	assert.Regexp(t, `^INITIALIZER\s+FUNCTION\s+COVERAGE$`, lines[9])
+	assert.Regexp(t, `^example/path/registry\.go:4:\s+defaultRegistry\s+0\.0%$`, lines[11])
+	assert.Regexp(t, `^example/path/registry\.go:9:\s+init\s+100\.0%$`, lines[12])
+	assert.Equal(t, "Initializers: 75.00%, not included in the total", lines[13])
+}
+
+func TestFormatTerminalMethods(t *testing.T) {
+	coverage := cover.Result{
+		Coverage: []cover.Coverage{
+			{Path: "example/path/file1.go:3:", Name: "String", Receiver: "T", Package: "example/path", Coverage: 100},
+			{Path: "example/path/file1.go:7:", Name: "String", Receiver: "*U", Package: "example/path", Coverage: 0},
+		},
+		TestHelpers: []cover.Coverage{
+			{Path: "example/path/file1_test.go:5:", Name: "Close", Receiver: "*fixture", Package: "example/path"},
+		},
+		Initializers: []cover.Coverage{
+			{Path: "example/path/registry.go:4:", Name: "Register", Receiver: "registry", Package: "example/path", Coverage: 100},
+		},
+	}
+
+	result, err := formatTerminal(coverage, terminalOptions{})
+	require.NoError(t, err)
+
+	assert.Regexp(t, `  file1\.go:3:\s+T\.String\s+100\.0%`, result)
+	assert.Regexp(t, `  file1\.go:7:\s+\(\*U\)\.String\s+0\.0%`, result)
+	assert.Regexp(t, `example/path/file1_test\.go:5:\s+\(\*fixture\)\.Close\s+0`, result)
+	assert.Regexp(t, `example/path/registry\.go:4:\s+registry\.Register\s+100\.0%`, result)
+}
+
+func TestFormatTerminalColor(t *testing.T) {
+	result, err := formatTerminal(terminalTestCoverage, terminalOptions{color: true})
+	require.NoError(t, err)
+
+	assert.Contains(t, result, colorGreen+"100.0%"+colorReset)
+	assert.Contains(t, result, colorYellow+"50.0%"+colorReset)
+	assert.Contains(t, result, colorRed+"0.0%"+colorReset)
+}
+
+func TestTruncatePath(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		width          int
+		expectedResult string
+	}{
+		{
+			name:           "fits",
+			path:           "pkg/file.go:1:",
+			width:          20,
+			expectedResult: "pkg/file.go:1:",
+		},
+		{
+			name:           "truncated from the left",
+			path:           "example.com/long/pkg/file.go:1:",
+			width:          14,
+			expectedResult: ".../file.go:1:",
+		},
+		{
+			name:           "too narrow for an ellipsis",
+			path:           "pkg/file.go:1:",
+			width:          3,
+			expectedResult: "pkg/file.go:1:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, truncatePath(tt.path, tt.width))
+		})
+	}
+}
+
+func TestFormatTerminalWidth(t *testing.T) {
+	result, err := formatTerminal(terminalTestCoverage, terminalOptions{width: 36})
+	require.NoError(t, err)
+
+	for _, line := range strings.Split(result, "\n")[2:] {
+		assert.LessOrEqual(t, len(line), 36, line)
+	}
+	assert.Contains(t, result, "...")
+	assert.Contains(t, result, "\n  ...o:7:   Function2")
 }
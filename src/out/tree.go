@@ -0,0 +1,190 @@
+package out
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+type callNode struct {
+	Function cover.Function
+	Coverage *cover.Coverage
+	Children []*callNode
+	// Cumulative is the total coverage of the function and every function reachable from it.
+	Cumulative float64
+	// Shared is set when the function was already expanded elsewhere in the tree.
+	Shared bool
+	// Cycle is set when the function is one of its own ancestors.
+	Cycle bool
+	// Setup is set for the test's TestMain and the functions below it, which
+	// run around every test in the package as shared setup.
+	Setup bool
+}
+
+func buildCallTree(test cover.Test, coverage []cover.Coverage) *callNode {
+	byFunction := coverageByFunction(coverage)
+
+	callees := map[cover.Function][]cover.Function{}
+	if test.TestMain != nil {
+		// TestMain runs around the test, so its calls are shown below it.
+		callees[test.Function] = append(callees[test.Function], *test.TestMain)
+	}
+	for _, call := range test.Calls {
+		callees[call.Caller] = append(callees[call.Caller], call.Callee)
+	}
+
+	expanded := map[cover.Function]bool{}
+	ancestors := map[cover.Function]bool{}
+
+	var build func(fn cover.Function, setup bool) *callNode
+	build = func(fn cover.Function, setup bool) *callNode {
+		node := &callNode{
+			Function:   fn,
+			Coverage:   byFunction[fn],
+			Cumulative: cover.TotalCoverage(reachableCoverage(fn, callees, byFunction)),
+			Setup:      setup || test.TestMain != nil && fn == *test.TestMain,
+		}
+		if ancestors[fn] {
+			node.Cycle = true
+			return node
+		}
+		if expanded[fn] {
+			node.Shared = true
+			return node
+		}
+
+		expanded[fn] = true
+		ancestors[fn] = true
+		for _, callee := range callees[fn] {
+			node.Children = append(node.Children, build(callee, node.Setup))
+		}
+		ancestors[fn] = false
+
+		return node
+	}
+
+	return build(test.Function, false)
+}
+
+// reachableCoverage returns the coverage of fn and every function it reaches
+// through callees, each counted once.
+func reachableCoverage(fn cover.Function, callees map[cover.Function][]cover.Function, byFunction map[cover.Function]*cover.Coverage) []cover.Coverage {
+	reachable := []cover.Coverage{}
+	visited := map[cover.Function]bool{}
+
+	var visit func(fn cover.Function)
+	visit = func(fn cover.Function) {
+		if visited[fn] {
+			return
+		}
+		visited[fn] = true
+
+		if funcCoverage, ok := byFunction[fn]; ok {
+			reachable = append(reachable, *funcCoverage)
+		}
+		for _, callee := range callees[fn] {
+			visit(callee)
+		}
+	}
+	visit(fn)
+
+	return reachable
+}
+
+func coverageByFunction(coverage []cover.Coverage) map[cover.Function]*cover.Coverage {
+	byFunction := make(map[cover.Function]*cover.Coverage, len(coverage))
+	for i := range coverage {
+		byFunction[coverage[i].Function()] = &coverage[i]
+	}
+
+	return byFunction
+}
+
+// formatTree renders the call tree of each test, with the coverage of every
+// function and the cumulative coverage of the subtree below it.
+func formatTree(coverage cover.Result, options terminalOptions) string {
+	var result strings.Builder
+
+	for i, test := range coverage.Tests {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+
+		root := buildCallTree(test, coverage.Coverage)
+		result.WriteString(treeNodeLabel(root, options))
+		result.WriteString("\n")
+		writeTreeChildren(&result, root, "", options)
+	}
+
+	result.WriteString(fmt.Sprintf("Total: %.2f%%", coverage.ApproxTotalCoverage))
+
+	return result.String()
+}
+
+func writeTreeChildren(result *strings.Builder, node *callNode, prefix string, options terminalOptions) {
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		result.WriteString(prefix + branch + treeNodeLabel(child, options) + "\n")
+		writeTreeChildren(result, child, prefix+indent, options)
+	}
+}
+
+func treeNodeLabel(node *callNode, options terminalOptions) string {
+	percentage := func(value float64) string {
+		str := fmt.Sprintf("%.1f%%", value)
+		if options.color {
+			str = colorize(str, value)
+		}
+		return str
+	}
+
+	label := path.Base(node.Function.Package) + "." + functionName(node.Function)
+	if node.Coverage != nil {
+		label += " " + percentage(node.Coverage.Coverage)
+	}
+
+	if node.Setup {
+		label += " (shared setup)"
+	}
+
+	switch {
+	case node.Cycle:
+		label += " (cycle)"
+	case node.Shared:
+		label += " (shared, subtree " + percentage(node.Cumulative) + ")"
+	case len(node.Children) > 0:
+		label += " (subtree " + percentage(node.Cumulative) + ")"
+	}
+
+	return label
+}
+
+// functionName returns the name of fn, qualified by the receiver if fn is a
+// method, like "(*Server).Close".
+func functionName(fn cover.Function) string {
+	switch {
+	case fn.Receiver == "":
+		return fn.Name
+	case strings.HasPrefix(fn.Receiver, "*"):
+		return "(" + fn.Receiver + ")." + fn.Name
+	default:
+		return fn.Receiver + "." + fn.Name
+	}
+}
+
+// testNames returns the names of tests, as reported next to the functions
+// they reach.
+func testNames(tests []cover.Function) []string {
+	names := make([]string, len(tests))
+	for i, test := range tests {
+		names[i] = test.Name
+	}
+
+	return names
+}
@@ -0,0 +1,219 @@
+package out
+
+import (
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCallTree(t *testing.T) {
+	test := cover.Function{Package: "pkg", Name: "TestTop"}
+	top := cover.Function{Package: "pkg", Name: "Top"}
+	bottom := cover.Function{Package: "pkg", Name: "Bottom"}
+	shared := cover.Function{Package: "pkg/sub", Name: "Shared"}
+
+	coverage := []cover.Coverage{
+		{Package: "pkg", Name: "Top", Statements: 2, Coverage: 100},
+		{Package: "pkg", Name: "Bottom", Statements: 4, Coverage: 50},
+		{Package: "pkg/sub", Name: "Shared", Statements: 2, Coverage: 0},
+	}
+
+	root := buildCallTree(cover.Test{
+		Function: test,
+		Calls: []cover.Call{
+			{Caller: test, Callee: top},
+			{Caller: top, Callee: bottom},
+			{Caller: top, Callee: shared},
+			{Caller: bottom, Callee: shared},
+			{Caller: bottom, Callee: top},
+		},
+	}, coverage)
+
+	assert.Equal(t, test, root.Function)
+	assert.Nil(t, root.Coverage)
+	assert.Equal(t, 50.0, root.Cumulative)
+	require.Len(t, root.Children, 1)
+
+	topNode := root.Children[0]
+	assert.Equal(t, top, topNode.Function)
+	require.NotNil(t, topNode.Coverage)
+	assert.Equal(t, 100.0, topNode.Coverage.Coverage)
+	require.Len(t, topNode.Children, 2)
+
+	bottomNode := topNode.Children[0]
+	assert.Equal(t, bottom, bottomNode.Function)
+	require.Len(t, bottomNode.Children, 2)
+
+	sharedNode := bottomNode.Children[0]
+	assert.Equal(t, shared, sharedNode.Function)
+	assert.False(t, sharedNode.Shared)
+	assert.Empty(t, sharedNode.Children)
+
+	cycleNode := bottomNode.Children[1]
+	assert.Equal(t, top, cycleNode.Function)
+	assert.True(t, cycleNode.Cycle)
+	assert.Empty(t, cycleNode.Children)
+
+	repeatedNode := topNode.Children[1]
+	assert.Equal(t, shared, repeatedNode.Function)
+	assert.True(t, repeatedNode.Shared)
+}
+
+func TestBuildCallTreeMethods(t *testing.T) {
+	test := cover.Function{Package: "pkg", Name: "TestString"}
+	a := cover.Function{Package: "pkg", Receiver: "A", Name: "String"}
+	b := cover.Function{Package: "pkg", Receiver: "*B", Name: "String"}
+
+	coverage := []cover.Coverage{
+		{Package: "pkg", Receiver: "A", Name: "String", Statements: 1, Coverage: 100},
+		{Package: "pkg", Receiver: "*B", Name: "String", Statements: 1, Coverage: 0},
+	}
+
+	root := buildCallTree(cover.Test{
+		Function: test,
+		Calls: []cover.Call{
+			{Caller: test, Callee: a},
+			{Caller: test, Callee: b},
+		},
+	}, coverage)
+
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, a, root.Children[0].Function)
+	assert.False(t, root.Children[0].Shared)
+	assert.Equal(t, 100.0, root.Children[0].Coverage.Coverage)
+	assert.Equal(t, b, root.Children[1].Function)
+	assert.False(t, root.Children[1].Shared)
+	assert.Equal(t, 0.0, root.Children[1].Coverage.Coverage)
+
+	assert.Equal(t, "pkg.A.String 100.0%", treeNodeLabel(root.Children[0], terminalOptions{}))
+	assert.Equal(t, "pkg.(*B).String 0.0%", treeNodeLabel(root.Children[1], terminalOptions{}))
+}
+
+func TestReachableCoverage(t *testing.T) {
+	top := cover.Function{Package: "pkg", Name: "Top"}
+	bottom := cover.Function{Package: "pkg", Name: "Bottom"}
+	shared := cover.Function{Package: "pkg/sub", Name: "Shared"}
+
+	coverage := []cover.Coverage{
+		{Package: "pkg", Name: "Top", Statements: 2, Coverage: 100},
+		{Package: "pkg", Name: "Bottom", Statements: 4, Coverage: 50},
+		{Package: "pkg/sub", Name: "Shared", Statements: 2, Coverage: 0},
+	}
+	callees := map[cover.Function][]cover.Function{
+		top:    {bottom, shared},
+		bottom: {shared, top},
+	}
+
+	tests := []struct {
+		name           string
+		fn             cover.Function
+		expectedResult []string
+	}{
+		{
+			name:           "cycle is counted once",
+			fn:             bottom,
+			expectedResult: []string{"Bottom", "Shared", "Top"},
+		},
+		{
+			name:           "leaf",
+			fn:             shared,
+			expectedResult: []string{"Shared"},
+		},
+		{
+			name:           "function without coverage",
+			fn:             cover.Function{Package: "pkg", Name: "TestTop"},
+			expectedResult: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{}
+			for _, funcCoverage := range reachableCoverage(tt.fn, callees, coverageByFunction(coverage)) {
+				names = append(names, funcCoverage.Name)
+			}
+			assert.Equal(t, tt.expectedResult, names)
+		})
+	}
+}
+
+func TestFormatTree(t *testing.T) {
+	test := cover.Function{Package: "example.com/pkg", Name: "TestTop"}
+	top := cover.Function{Package: "example.com/pkg", Name: "Top"}
+	bottom := cover.Function{Package: "example.com/pkg", Name: "Bottom"}
+	shared := cover.Function{Package: "example.com/pkg/sub", Name: "Shared"}
+
+	result := formatTree(cover.Result{
+		Coverage: []cover.Coverage{
+			{Package: "example.com/pkg", Name: "Top", Statements: 2, Coverage: 100},
+			{Package: "example.com/pkg", Name: "Bottom", Statements: 4, Coverage: 50},
+			{Package: "example.com/pkg/sub", Name: "Shared", Statements: 2, Coverage: 0},
+		},
+		ApproxTotalCoverage: 50,
+		Tests: []cover.Test{
+			{
+				Function: test,
+				Calls: []cover.Call{
+					{Caller: test, Callee: top},
+					{Caller: top, Callee: bottom},
+					{Caller: top, Callee: shared},
+					{Caller: bottom, Callee: shared},
+					{Caller: bottom, Callee: top},
+				},
+			},
+		},
+	}, terminalOptions{})
+
+	expected := `pkg.TestTop (subtree 50.0%)
+└── pkg.Top 100.0% (subtree 50.0%)
+    ├── pkg.Bottom 50.0% (subtree 50.0%)
+    │   ├── sub.Shared 0.0%
+    │   └── pkg.Top 100.0% (cycle)
+    └── sub.Shared 0.0% (shared, subtree 0.0%)
+Total: 50.00%`
+
+	assert.Equal(t, expected, result)
+}
+
+func TestFormatTreeTestMain(t *testing.T) {
+	test := cover.Function{Package: "example.com/pkg", Name: "TestTop"}
+	testMain := cover.Function{Package: "example.com/pkg", Name: "TestMain"}
+	startServer := cover.Function{Package: "example.com/pkg", Name: "startServer"}
+	top := cover.Function{Package: "example.com/pkg", Name: "Top"}
+
+	coverage := cover.Result{
+		Coverage: []cover.Coverage{
+			{Package: "example.com/pkg", Name: "startServer", Statements: 2, Coverage: 100, Setup: true},
+			{Package: "example.com/pkg", Name: "Top", Statements: 2, Coverage: 0},
+		},
+		Tests: []cover.Test{
+			{
+				Function: test,
+				Calls: []cover.Call{
+					{Caller: test, Callee: top},
+					{Caller: testMain, Callee: startServer},
+				},
+				TestMain: &testMain,
+			},
+		},
+	}
+
+	root := buildCallTree(coverage.Tests[0], coverage.Coverage)
+	assert.False(t, root.Setup)
+	assert.Equal(t, 50.0, root.Cumulative)
+	require.Len(t, root.Children, 2)
+	assert.True(t, root.Children[0].Setup)
+	require.Len(t, root.Children[0].Children, 1)
+	assert.True(t, root.Children[0].Children[0].Setup)
+	assert.False(t, root.Children[1].Setup)
+
+	expected := `pkg.TestTop (subtree 50.0%)
+├── pkg.TestMain (shared setup) (subtree 100.0%)
+│   └── pkg.startServer 100.0% (shared setup)
+└── pkg.Top 0.0%
+Total: 0.00%`
+
+	assert.Equal(t, expected, formatTree(coverage, terminalOptions{}))
+}
@@ -0,0 +1,593 @@
+package out
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"golang.org/x/term"
+)
+
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+
+	colorDim = "\033[2m"
+
+	tuiHelp       = "↑↓ move  ←→ collapse/expand  enter source  / package  c coverage  r re-run  q quit"
+	tuiSourceHelp = "↑↓ scroll  esc back  q quit"
+)
+
+// RerunFunc re-runs the analysis for a single test.
+type RerunFunc func(test cover.Test) (cover.Result, error)
+
+// Browse runs an interactive browser over coverage in the terminal until the
+// user quits. Tests expand into their call trees and functions open their
+// annotated source; rerun is called to re-run the test under the cursor.
+func Browse(coverage cover.Result, rerun RerunFunc) error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return fmt.Errorf("stdin and stdout must be a terminal")
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %v", err)
+	}
+	defer term.Restore(in, state)
+
+	// Switch to the alternate screen and hide the cursor while browsing.
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	b := newBrowser(coverage, rerun)
+	b.color = os.Getenv("NO_COLOR") == ""
+	buf := make([]byte, 16)
+	for !b.quit {
+		if width, height, err := term.GetSize(out); err == nil && width > 0 && height > 0 {
+			b.width, b.height = width, height
+		}
+		fmt.Print("\033[H\033[2J" + strings.Join(b.render(), "\r\n"))
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return fmt.Errorf("failed to read input: %v", err)
+		}
+		b.handleKey(parseKey(buf[:n]))
+	}
+
+	return nil
+}
+
+// parseKey names the key for a chunk of terminal input. Printable keys are
+// returned as they are.
+func parseKey(input []byte) string {
+	switch string(input) {
+	case "\033[A", "\033OA":
+		return keyUp
+	case "\033[B", "\033OB":
+		return keyDown
+	case "\033[C", "\033OC":
+		return keyRight
+	case "\033[D", "\033OD":
+		return keyLeft
+	case "\033[5~":
+		return keyPageUp
+	case "\033[6~":
+		return keyPageDown
+	case "\r", "\n":
+		return keyEnter
+	case "\033":
+		return keyEscape
+	case "\x7f", "\b":
+		return keyBackspace
+	}
+
+	return string(input)
+}
+
+type browserRow struct {
+	node  *callNode
+	test  int
+	depth int
+	// key identifies the row's position in the tree, to remember if it is expanded.
+	key string
+}
+
+// browser is the state of the interactive browser. It is kept apart from the
+// terminal so that it can be driven by key names.
+type browser struct {
+	coverage cover.Result
+	rerun    RerunFunc
+	// results holds the result of each test that was re-run, by test index.
+	results map[int]cover.Result
+	trees   map[int]*callNode
+
+	expanded map[string]bool
+	cursor   int
+	offset   int
+
+	packageFilter string
+	// coverageFilter shows only functions below this coverage, or every function if 0.
+	coverageFilter float64
+
+	// prompt is the filter being edited: "/" for the package and "c" for coverage.
+	prompt string
+	input  string
+
+	source       *cover.Coverage
+	sourceOffset int
+
+	status string
+	// color is unset when NO_COLOR is, to render without escape sequences.
+	color  bool
+	width  int
+	height int
+	quit   bool
+}
+
+func newBrowser(coverage cover.Result, rerun RerunFunc) *browser {
+	return &browser{
+		coverage: coverage,
+		rerun:    rerun,
+		results:  map[int]cover.Result{},
+		trees:    map[int]*callNode{},
+		expanded: map[string]bool{},
+		width:    80,
+		height:   24,
+	}
+}
+
+func (b *browser) tree(test int) *callNode {
+	if tree, ok := b.trees[test]; ok {
+		return tree
+	}
+
+	result, ok := b.results[test]
+	if !ok {
+		result = b.coverage
+	}
+
+	for _, t := range result.Tests {
+		if t.Function == b.coverage.Tests[test].Function {
+			b.trees[test] = buildCallTree(t, result.Coverage)
+			return b.trees[test]
+		}
+	}
+
+	b.trees[test] = &callNode{Function: b.coverage.Tests[test].Function}
+	return b.trees[test]
+}
+
+func (b *browser) filtered() bool {
+	return b.packageFilter != "" || b.coverageFilter > 0
+}
+
+func (b *browser) matches(node *callNode) bool {
+	if node.Coverage == nil {
+		return false
+	}
+	if !strings.Contains(node.Function.Package, b.packageFilter) {
+		return false
+	}
+
+	return b.coverageFilter == 0 || node.Coverage.Coverage < b.coverageFilter
+}
+
+// visible reports whether node or one of its descendants passes the filters.
+func (b *browser) visible(node *callNode) bool {
+	if !b.filtered() || b.matches(node) {
+		return true
+	}
+
+	for _, child := range node.Children {
+		if b.visible(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *browser) rows() []browserRow {
+	rows := []browserRow{}
+
+	var add func(node *callNode, test, depth int, key string)
+	add = func(node *callNode, test, depth int, key string) {
+		if !b.visible(node) {
+			return
+		}
+
+		rows = append(rows, browserRow{node: node, test: test, depth: depth, key: key})
+		if !b.expanded[key] && !b.filtered() {
+			return
+		}
+		for i, child := range node.Children {
+			add(child, test, depth+1, key+"/"+strconv.Itoa(i))
+		}
+	}
+
+	for i := range b.coverage.Tests {
+		add(b.tree(i), i, 0, strconv.Itoa(i))
+	}
+
+	return rows
+}
+
+// listHeight is the number of rows shown, leaving space for the header,
+// status and help lines.
+func (b *browser) listHeight() int {
+	if b.height < 4 {
+		return 1
+	}
+	return b.height - 3
+}
+
+func (b *browser) handleKey(key string) {
+	b.status = ""
+
+	switch {
+	case b.prompt != "":
+		b.handlePromptKey(key)
+	case b.source != nil:
+		b.handleSourceKey(key)
+	default:
+		b.handleListKey(key)
+	}
+}
+
+func (b *browser) handleListKey(key string) {
+	rows := b.rows()
+
+	switch key {
+	case "q", "\x03":
+		b.quit = true
+	case keyUp, "k":
+		b.cursor--
+	case keyDown, "j":
+		b.cursor++
+	case keyPageUp:
+		b.cursor -= b.listHeight()
+	case keyPageDown:
+		b.cursor += b.listHeight()
+	case keyRight, "l":
+		if b.cursor < len(rows) {
+			b.expanded[rows[b.cursor].key] = true
+		}
+	case keyLeft, "h":
+		if b.cursor < len(rows) {
+			b.collapse(rows)
+		}
+	case keyEnter:
+		if b.cursor < len(rows) {
+			b.open(rows[b.cursor])
+		}
+	case "/", "c":
+		b.prompt = key
+		b.input = ""
+	case "r":
+		if b.cursor < len(rows) {
+			b.rerunTest(rows[b.cursor].test)
+		}
+	}
+
+	b.clampCursor(len(b.rows()))
+}
+
+// collapse closes the row under the cursor, or moves to its parent if it is
+// already closed.
+func (b *browser) collapse(rows []browserRow) {
+	row := rows[b.cursor]
+	if b.expanded[row.key] {
+		b.expanded[row.key] = false
+		return
+	}
+
+	i := strings.LastIndex(row.key, "/")
+	if i < 0 {
+		return
+	}
+	parent := row.key[:i]
+	for i, r := range rows {
+		if r.key == parent {
+			b.cursor = i
+			b.expanded[parent] = false
+			return
+		}
+	}
+}
+
+// open toggles a test's call tree, or shows a function's annotated source.
+func (b *browser) open(row browserRow) {
+	if row.depth == 0 {
+		b.expanded[row.key] = !b.expanded[row.key]
+		return
+	}
+
+	if row.node.Coverage == nil {
+		b.status = fmt.Sprintf("no coverage for %s", functionName(row.node.Function))
+		return
+	}
+
+	b.source = row.node.Coverage
+	b.sourceOffset = 0
+}
+
+func (b *browser) rerunTest(test int) {
+	if b.rerun == nil {
+		b.status = "re-running is not available"
+		return
+	}
+
+	fn := b.coverage.Tests[test].Function
+	result, err := b.rerun(b.coverage.Tests[test])
+	if err != nil {
+		b.status = fmt.Sprintf("failed to re-run %s: %v", fn.Name, err)
+		return
+	}
+
+	b.results[test] = result
+	delete(b.trees, test)
+	b.status = fmt.Sprintf("re-ran %s: %.2f%%", fn.Name, result.ApproxTotalCoverage)
+}
+
+func (b *browser) clampCursor(rows int) {
+	if b.cursor >= rows {
+		b.cursor = rows - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+b.listHeight() {
+		b.offset = b.cursor - b.listHeight() + 1
+	}
+}
+
+func (b *browser) handlePromptKey(key string) {
+	switch key {
+	case keyEscape:
+		b.prompt = ""
+	case keyEnter:
+		b.applyFilter()
+	case keyBackspace:
+		if len(b.input) > 0 {
+			_, size := utf8.DecodeLastRuneInString(b.input)
+			b.input = b.input[:len(b.input)-size]
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 && key >= " " {
+			b.input += key
+		}
+	}
+}
+
+func (b *browser) applyFilter() {
+	prompt := b.prompt
+	b.prompt = ""
+
+	if prompt == "/" {
+		b.packageFilter = b.input
+		b.clampCursor(len(b.rows()))
+		return
+	}
+
+	if b.input == "" {
+		b.coverageFilter = 0
+		b.clampCursor(len(b.rows()))
+		return
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(b.input, "%"), 64)
+	if err != nil {
+		b.status = fmt.Sprintf("invalid coverage %q", b.input)
+		return
+	}
+	b.coverageFilter = threshold
+	b.clampCursor(len(b.rows()))
+}
+
+func (b *browser) handleSourceKey(key string) {
+	switch key {
+	case "q", "\x03":
+		b.quit = true
+	case keyEscape, keyLeft, "h":
+		b.source = nil
+	case keyUp, "k":
+		b.sourceOffset--
+	case keyDown, "j":
+		b.sourceOffset++
+	case keyPageUp:
+		b.sourceOffset -= b.listHeight()
+	case keyPageDown:
+		b.sourceOffset += b.listHeight()
+	}
+
+	if b.sourceOffset < 0 {
+		b.sourceOffset = 0
+	}
+}
+
+// render returns the lines of the screen.
+func (b *browser) render() []string {
+	var lines []string
+	if b.source != nil {
+		lines = b.renderSource()
+	} else {
+		lines = b.renderList()
+	}
+
+	for len(lines) < b.height-2 {
+		lines = append(lines, "")
+	}
+
+	status := b.status
+	switch {
+	case b.prompt == "/":
+		status = "package: " + b.input
+	case b.prompt == "c":
+		status = "below coverage %: " + b.input
+	}
+
+	help := tuiHelp
+	if b.source != nil {
+		help = tuiSourceHelp
+	}
+	lines = append(lines, status, b.paint(colorDim, help))
+
+	for i := range lines {
+		lines[i] = truncateLine(lines[i], b.width)
+	}
+
+	return lines
+}
+
+func (b *browser) renderList() []string {
+	header := fmt.Sprintf("deepcover  Total: %.2f%%", b.coverage.ApproxTotalCoverage)
+	if b.packageFilter != "" {
+		header += fmt.Sprintf("  package: %s", b.packageFilter)
+	}
+	if b.coverageFilter > 0 {
+		header += fmt.Sprintf("  below: %.1f%%", b.coverageFilter)
+	}
+	lines := []string{header}
+
+	rows := b.rows()
+	if len(rows) == 0 {
+		return append(lines, "no matching functions")
+	}
+
+	b.clampCursor(len(rows))
+	end := b.offset + b.listHeight()
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	for i := b.offset; i < end; i++ {
+		row := rows[i]
+
+		cursor := "  "
+		if i == b.cursor {
+			cursor = "> "
+		}
+
+		marker := "  "
+		if len(row.node.Children) > 0 {
+			marker = "▸ "
+			if b.expanded[row.key] || b.filtered() {
+				marker = "▾ "
+			}
+		}
+
+		label := treeNodeLabel(row.node, terminalOptions{color: b.color})
+		if _, ok := b.results[row.test]; ok && row.depth == 0 {
+			label += " [re-run]"
+		}
+
+		lines = append(lines, cursor+strings.Repeat("  ", row.depth)+marker+label)
+	}
+
+	return lines
+}
+
+func (b *browser) renderSource() []string {
+	funcCoverage := *b.source
+	percentage := fmt.Sprintf("%.1f%%", funcCoverage.Coverage)
+	if b.color {
+		percentage = colorize(percentage, funcCoverage.Coverage)
+	}
+	lines := []string{fmt.Sprintf("%s  %s:%d  %s",
+		functionName(funcCoverage.Function()),
+		relativeFile(b.coverage.ModuleDir, funcCoverage),
+		funcCoverage.StartLine,
+		percentage)}
+
+	source, err := annotateSource(funcCoverage)
+	if err != nil {
+		return append(lines, err.Error())
+	}
+
+	if b.sourceOffset > len(source)-1 {
+		b.sourceOffset = len(source) - 1
+	}
+	if b.sourceOffset < 0 {
+		b.sourceOffset = 0
+	}
+
+	end := b.sourceOffset + b.listHeight()
+	if end > len(source) {
+		end = len(source)
+	}
+
+	for _, line := range source[b.sourceOffset:end] {
+		gutter := " "
+		switch line.Class {
+		case "covered":
+			gutter = b.paint(colorGreen, "+")
+		case "uncovered":
+			gutter = b.paint(colorRed, "-")
+		case "partial":
+			gutter = b.paint(colorYellow, "~")
+		}
+
+		text := strings.ReplaceAll(line.Text, "\t", "    ")
+		lines = append(lines, fmt.Sprintf("%5d %s %s", line.Number, gutter, text))
+	}
+
+	return lines
+}
+
+// paint wraps s in color, unless the browser renders without color.
+func (b *browser) paint(color, s string) string {
+	if !b.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// truncateLine shortens s to width visible characters, not counting ANSI
+// escape sequences.
+func truncateLine(s string, width int) string {
+	var result strings.Builder
+	visible := 0
+	escaped := false
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			end := strings.IndexByte(s[i:], 'm')
+			if end < 0 {
+				break
+			}
+			result.WriteString(s[i : i+end+1])
+			i += end + 1
+			escaped = true
+			continue
+		}
+
+		if visible == width {
+			if escaped {
+				result.WriteString(colorReset)
+			}
+			break
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		result.WriteRune(r)
+		visible++
+		i += size
+	}
+
+	return result.String()
+}
@@ -0,0 +1,206 @@
+package out
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+	}{
+		{input: "\033[A", expectedResult: keyUp},
+		{input: "\033OB", expectedResult: keyDown},
+		{input: "\033[5~", expectedResult: keyPageUp},
+		{input: "\r", expectedResult: keyEnter},
+		{input: "\033", expectedResult: keyEscape},
+		{input: "\x7f", expectedResult: keyBackspace},
+		{input: "q", expectedResult: "q"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedResult, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, parseKey([]byte(tt.input)))
+		})
+	}
+}
+
+func browserRowNames(b *browser) []string {
+	names := []string{}
+	for _, row := range b.rows() {
+		names = append(names, strings.Repeat(" ", row.depth)+row.node.Function.Name)
+	}
+	return names
+}
+
+func TestBrowserNavigation(t *testing.T) {
+	b := newBrowser(htmlTestCoverage(t), nil)
+	b.color = true
+	assert.Equal(t, []string{"TestFunction1"}, browserRowNames(b))
+
+	b.handleKey(keyEnter)
+	assert.Equal(t, []string{"TestFunction1", " Function1"}, browserRowNames(b))
+
+	b.handleKey(keyDown)
+	b.handleKey(keyDown)
+	assert.Equal(t, 1, b.cursor)
+
+	b.handleKey(keyEnter)
+	require.NotNil(t, b.source)
+	assert.Equal(t, "Function1", b.source.Name)
+
+	screen := strings.Join(b.render(), "\n")
+	assert.Contains(t, screen, "func Function1(x int) int {")
+	assert.Contains(t, screen, colorRed+"-"+colorReset+"     return -x")
+
+	b.handleKey(keyEscape)
+	assert.Nil(t, b.source)
+
+	b.handleKey(keyLeft)
+	assert.Equal(t, 0, b.cursor)
+	assert.Equal(t, []string{"TestFunction1"}, browserRowNames(b))
+
+	b.handleKey("q")
+	assert.True(t, b.quit)
+}
+
+func TestBrowserNoColor(t *testing.T) {
+	b := newBrowser(htmlTestCoverage(t), nil)
+	b.width, b.height = 20, 10
+
+	b.handleKey(keyEnter)
+	assert.NotContains(t, strings.Join(b.render(), "\n"), "\033")
+
+	b.handleKey(keyDown)
+	b.handleKey(keyEnter)
+	require.NotNil(t, b.source)
+
+	screen := strings.Join(b.render(), "\n")
+	assert.NotContains(t, screen, "\033")
+	assert.Contains(t, screen, "- ")
+}
+
+func TestBrowserFilter(t *testing.T) {
+	coverage := htmlTestCoverage(t)
+	coverage.Coverage[0].Statements = 3
+
+	tests := []struct {
+		name           string
+		keys           []string
+		expectedResult []string
+		expectedStatus string
+	}{
+		{
+			name:           "below coverage",
+			keys:           []string{"c", "8", "0", keyEnter},
+			expectedResult: []string{"TestFunction1", " Function1"},
+		},
+		{
+			name:           "no function below coverage",
+			keys:           []string{"c", "5", "0", keyEnter},
+			expectedResult: []string{},
+		},
+		{
+			name:           "package",
+			keys:           []string{"/", "o", "t", "h", "e", "r", keyEnter},
+			expectedResult: []string{},
+		},
+		{
+			name:           "edited package",
+			keys:           []string{"/", "x", keyBackspace, "p", "a", "t", "h", keyEnter},
+			expectedResult: []string{"TestFunction1", " Function1"},
+		},
+		{
+			name:           "cancelled",
+			keys:           []string{"/", "x", keyEscape},
+			expectedResult: []string{"TestFunction1"},
+		},
+		{
+			name:           "invalid coverage",
+			keys:           []string{"c", "x", keyEnter},
+			expectedResult: []string{"TestFunction1"},
+			expectedStatus: `invalid coverage "x"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBrowser(coverage, nil)
+			for _, key := range tt.keys {
+				b.handleKey(key)
+			}
+
+			assert.Equal(t, tt.expectedResult, browserRowNames(b))
+			assert.Equal(t, tt.expectedStatus, b.status)
+		})
+	}
+}
+
+func TestBrowserRerun(t *testing.T) {
+	coverage := htmlTestCoverage(t)
+
+	rerun := func(test cover.Test) (cover.Result, error) {
+		assert.Equal(t, "TestFunction1", test.Function.Name)
+
+		result := htmlTestCoverage(t)
+		result.Coverage[0].Coverage = 100
+		result.ApproxTotalCoverage = 100
+		return result, nil
+	}
+
+	b := newBrowser(coverage, rerun)
+	b.handleKey("r")
+	assert.Equal(t, "re-ran TestFunction1: 100.00%", b.status)
+
+	b.handleKey(keyEnter)
+	rows := b.rows()
+	require.Len(t, rows, 2)
+	assert.Equal(t, 100.0, rows[1].node.Coverage.Coverage)
+	assert.Contains(t, strings.Join(b.render(), "\n"), "[re-run]")
+
+	b = newBrowser(coverage, func(cover.Test) (cover.Result, error) {
+		return cover.Result{}, fmt.Errorf("tests failed")
+	})
+	b.handleKey("r")
+	assert.Equal(t, "failed to re-run TestFunction1: tests failed", b.status)
+}
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		name           string
+		line           string
+		width          int
+		expectedResult string
+	}{
+		{
+			name:           "fits",
+			line:           "abc",
+			width:          5,
+			expectedResult: "abc",
+		},
+		{
+			name:           "truncated",
+			line:           "abcdef",
+			width:          3,
+			expectedResult: "abc",
+		},
+		{
+			name:           "escape sequences are not counted",
+			line:           colorGreen + "abc" + colorReset + "def",
+			width:          4,
+			expectedResult: colorGreen + "abc" + colorReset + "d" + colorReset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedResult, truncateLine(tt.line, tt.width))
+		})
+	}
+}
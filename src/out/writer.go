@@ -0,0 +1,133 @@
+package out
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/leobishop234/deepcover/src/cover"
+)
+
+// Writer writes a coverage report to path, or to the terminal if path is empty.
+type Writer interface {
+	Write(path string, coverage cover.Result, options Options) error
+}
+
+// WriterFunc adapts a function to the Writer interface.
+type WriterFunc func(path string, coverage cover.Result, options Options) error
+
+func (f WriterFunc) Write(path string, coverage cover.Result, options Options) error {
+	return f(path, coverage, options)
+}
+
+// Options are the settings shared by all writers; each writer uses the ones
+// relevant to its format.
+type Options struct {
+	// Threshold is the coverage percentage below which a dependency is reported.
+	Threshold float64
+	// Baseline is a previous result to compare against, if any.
+	Baseline *cover.Result
+	// Template is the path of the text/template to render.
+	Template string
+	// Sort is the key the terminal table is sorted by, see SortKeys.
+	Sort string
+	// View is how coverage is shown in the terminal, see Views.
+	View string
+}
+
+var (
+	writersMu sync.RWMutex
+	writers   = map[string]Writer{}
+)
+
+// Register makes a writer available under the given format name. It panics if
+// the name is already registered or the writer is nil.
+func Register(format string, writer Writer) {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+
+	if writer == nil {
+		panic("out: Register writer is nil")
+	}
+	if _, ok := writers[format]; ok {
+		panic("out: Register called twice for format " + format)
+	}
+	writers[format] = writer
+}
+
+func Lookup(format string) (Writer, bool) {
+	writersMu.RLock()
+	defer writersMu.RUnlock()
+
+	writer, ok := writers[format]
+	return writer, ok
+}
+
+// Formats returns the sorted names of the registered formats.
+func Formats() []string {
+	writersMu.RLock()
+	defer writersMu.RUnlock()
+
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// Output writes coverage to path using the writer registered for format.
+func Output(format, path string, coverage cover.Result, options Options) error {
+	writer, ok := Lookup(format)
+	if !ok {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	return writer.Write(path, coverage, options)
+}
+
+func init() {
+	Register("text", WriterFunc(func(path string, coverage cover.Result, options Options) error {
+		if path == "" {
+			return OutputTerminal(coverage, options)
+		}
+		return OutputFile(path, coverage)
+	}))
+	Register("json", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputJSON(path, coverage)
+	}))
+	Register("html", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputHTML(path, coverage)
+	}))
+	Register("cobertura", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputCobertura(path, coverage)
+	}))
+	Register("lcov", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputLCOV(path, coverage)
+	}))
+	Register("sonar", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputSonar(path, coverage)
+	}))
+	Register("sarif", WriterFunc(func(path string, coverage cover.Result, options Options) error {
+		return OutputSARIF(path, coverage, options.Threshold)
+	}))
+	Register("github", WriterFunc(func(path string, coverage cover.Result, options Options) error {
+		return OutputGitHub(path, coverage, options.Threshold)
+	}))
+	Register("markdown", WriterFunc(func(path string, coverage cover.Result, options Options) error {
+		return OutputMarkdown(path, coverage, options.Baseline)
+	}))
+	Register("csv", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputCSV(path, coverage)
+	}))
+	Register("tsv", WriterFunc(func(path string, coverage cover.Result, _ Options) error {
+		return OutputTSV(path, coverage)
+	}))
+	Register("template", WriterFunc(func(path string, coverage cover.Result, options Options) error {
+		if options.Template == "" {
+			return fmt.Errorf("no template provided")
+		}
+		return OutputTemplate(path, options.Template, coverage)
+	}))
+}
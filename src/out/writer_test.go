@@ -0,0 +1,100 @@
+package out
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leobishop234/deepcover/src/cover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinFormats(t *testing.T) {
+	assert.Equal(t, []string{
+		"cobertura", "csv", "github", "html", "json", "lcov", "markdown", "sarif", "sonar", "template", "text", "tsv",
+	}, Formats())
+}
+
+func TestRegister(t *testing.T) {
+	var gotPath string
+	var gotOptions Options
+	Register("test-register", WriterFunc(func(path string, _ cover.Result, options Options) error {
+		gotPath = path
+		gotOptions = options
+		return nil
+	}))
+	t.Cleanup(func() {
+		writersMu.Lock()
+		delete(writers, "test-register")
+		writersMu.Unlock()
+	})
+
+	_, ok := Lookup("test-register")
+	assert.True(t, ok)
+	assert.Contains(t, Formats(), "test-register")
+
+	require.NoError(t, Output("test-register", "report.out", fileTestCoverage, Options{Threshold: 80}))
+	assert.Equal(t, "report.out", gotPath)
+	assert.Equal(t, Options{Threshold: 80}, gotOptions)
+
+	assert.Panics(t, func() {
+		Register("test-register", WriterFunc(func(string, cover.Result, Options) error { return nil }))
+	})
+	assert.Panics(t, func() {
+		Register("test-nil", nil)
+	})
+}
+
+func TestOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		options     Options
+		expected    func(t *testing.T) string
+		expectError bool
+	}{
+		{
+			name:   "text file",
+			format: "text",
+			expected: func(t *testing.T) string {
+				return formatFile(fileTestCoverage)
+			},
+		},
+		{
+			name:   "json file",
+			format: "json",
+			expected: func(t *testing.T) string {
+				report, err := formatJSON(fileTestCoverage)
+				require.NoError(t, err)
+				return report
+			},
+		},
+		{
+			name:        "template without template path",
+			format:      "template",
+			expectError: true,
+		},
+		{
+			name:        "unknown format",
+			format:      "unknown",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report")
+			err := Output(tt.format, path, fileTestCoverage, tt.options)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			gotBytes, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected(t), string(gotBytes))
+		})
+	}
+}